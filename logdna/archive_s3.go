@@ -0,0 +1,62 @@
+package logdna
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Server-side encryption modes of S3 archives
+const (
+	s3SSES3  = "AES256"
+	s3SSEKMS = "aws:kms"
+)
+
+var s3SSEModes = []string{s3SSES3, s3SSEKMS}
+
+// e.g. arn:aws:iam::123456789012:role/logdna-archive, in any AWS partition
+var iamRoleARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+
+func validateIAMRoleARN(val interface{}, key string) (warns []string, errs []error) {
+	if v := val.(string); !iamRoleARNPattern.MatchString(v) {
+		errs = append(errs, fmt.Errorf("%q must be the ARN of an IAM role, e.g. arn:aws:iam::123456789012:role/name, got: %s", key, v))
+	}
+	return
+}
+
+// validateS3Endpoint checks that the endpoint of an S3-compatible store is an
+// absolute http(s) URL
+func validateS3Endpoint(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%q must be an http or https URL, got: %s", key, v))
+	}
+	return
+}
+
+func validateS3SSE(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if !containsString(s3SSEModes, v, false) {
+		errs = append(errs, fmt.Errorf("%q must be one of %v, got: %s", key, s3SSEModes, v))
+	}
+	return
+}
+
+// checkS3Config validates the settings of `s3_config` that depend on each other
+func checkS3Config(d *schema.ResourceDiff) error {
+	if d.Get("s3_config.#").(int) == 0 {
+		return nil
+	}
+	config := d.Get("s3_config.0").(map[string]interface{})
+
+	if config["kms_key_id"].(string) != "" && config["sse"].(string) != s3SSEKMS {
+		return fmt.Errorf("s3_config.kms_key_id requires sse = %q", s3SSEKMS)
+	}
+	if config["external_id"].(string) != "" && config["role_arn"].(string) == "" {
+		return fmt.Errorf("s3_config.external_id requires role_arn, it is only used when assuming the role")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveS3_validate(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, arn := range []string{"arn:aws:iam::123456789012:role/logdna", "arn:aws-us-gov:iam::123456789012:role/path/to/logdna-archive"} {
+		_, errs := validateIAMRoleARN(arn, "role_arn")
+		assert.Empty(errs, arn)
+	}
+	for _, arn := range []string{"logdna", "arn:aws:iam::1234:role/logdna", "arn:aws:iam::123456789012:user/logdna"} {
+		_, errs := validateIAMRoleARN(arn, "role_arn")
+		assert.Len(errs, 1, arn)
+	}
+
+	_, errs := validateS3Endpoint("https://minio.internal:9000", "endpoint")
+	assert.Empty(errs)
+	_, errs = validateS3Endpoint("minio.internal:9000", "endpoint")
+	assert.Len(errs, 1, "The scheme is required")
+
+	_, errs = validateS3SSE("aws:kms", "sse")
+	assert.Empty(errs)
+	_, errs = validateS3SSE("kms", "sse")
+	assert.Len(errs, 1)
+	assert.EqualError(errs[0], `"sse" must be one of [AES256 aws:kms], got: kms`)
+}
+
+func TestArchiveS3_checkS3Config(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceArchiveConfig()
+
+	diff := func(s3 map[string]interface{}) error {
+		s3["bucket"] = "logs"
+		_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"integration": "s3",
+			"s3_config":   []interface{}{s3},
+		}), nil)
+		return err
+	}
+
+	assert.Nil(diff(map[string]interface{}{"sse": "aws:kms", "kms_key_id": "alias/logs"}))
+	assert.EqualError(diff(map[string]interface{}{"sse": "AES256", "kms_key_id": "alias/logs"}), `s3_config.kms_key_id requires sse = "aws:kms"`)
+	assert.Nil(diff(map[string]interface{}{"role_arn": "arn:aws:iam::123456789012:role/logdna", "external_id": "abc"}))
+	assert.EqualError(diff(map[string]interface{}{"external_id": "abc"}), "s3_config.external_id requires role_arn, it is only used when assuming the role")
+}
+
+func TestArchiveS3_mapping(t *testing.T) {
+	assert := assert.New(t)
+
+	s3 := map[string]interface{}{
+		"bucket":      "logs",
+		"role_arn":    "arn:aws:iam::123456789012:role/logdna",
+		"external_id": "abc",
+		"region":      "eu-west-1",
+		"prefix":      "logdna/",
+		"endpoint":    "https://minio.internal:9000",
+		"sse":         "aws:kms",
+		"kms_key_id":  "alias/logs",
+	}
+	d := schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, map[string]interface{}{
+		"integration": "s3",
+		"s3_config":   []interface{}{s3},
+	})
+
+	c, err := generateArchiveConfig(d)
+	assert.Nil(err, "No errors")
+	body, err := json.Marshal(c)
+	assert.Nil(err, "No errors")
+	assert.JSONEq(`{
+		"integration": "s3",
+		"bucket": "logs",
+		"rolearn": "arn:aws:iam::123456789012:role/logdna",
+		"externalid": "abc",
+		"region": "eu-west-1",
+		"prefix": "logdna/",
+		"endpoint": "https://minio.internal:9000",
+		"sse": "aws:kms",
+		"kmskeyid": "alias/logs"
+	}`, string(body))
+
+	cn := archiveResponse{}
+	assert.Nil(json.Unmarshal(body, &cn))
+	d = schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, map[string]interface{}{})
+	assert.False(setArchiveConfig(cn, d).HasError(), "No errors")
+	assert.Equal([]interface{}{s3}, d.Get("s3_config"))
+
+	// Optional settings are left out of the request when they are not set
+	d = schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, map[string]interface{}{
+		"integration": "s3",
+		"s3_config":   []interface{}{map[string]interface{}{"bucket": "logs"}},
+	})
+	c, err = generateArchiveConfig(d)
+	assert.Nil(err, "No errors")
+	body, err = json.Marshal(c)
+	assert.Nil(err, "No errors")
+	assert.JSONEq(`{"integration": "s3", "bucket": "logs"}`, string(body))
+}
@@ -0,0 +1,145 @@
+package logdna
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Archive credentials are write-only: they are sent to the API but never
+// stored in state. State holds a salted hash of the last value sent instead,
+// so that a change in the configuration is still detected by comparing hashes.
+// The API masks credentials on GET, and these masks are never drift.
+
+// Credentials of each archive integration
+var archiveSecretFields = map[string][]string{
+	"ibm":    {"apikey"},
+	"azblob": {"accountkey"},
+	"dos":    {"accesskey", "secretkey"},
+	"swift":  {"password"},
+}
+
+const secretHashPrefix = "sha256:"
+
+// hashSecret returns `sha256:<salt>:<hash of the salt and secret>`
+func hashSecret(secret, salt string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return fmt.Sprintf("%s%s:%s", secretHashPrefix, salt, hex.EncodeToString(sum[:]))
+}
+
+// newSecretHash hashes the secret with a new random salt
+func newSecretHash(secret string) string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("[WARN] Cannot generate a random salt: %s", err)
+	}
+	return hashSecret(secret, hex.EncodeToString(b))
+}
+
+func isSecretHash(value string) bool {
+	return strings.HasPrefix(value, secretHashPrefix) && strings.Count(value, ":") == 2
+}
+
+// secretHashSalt returns the salt of a hash returned by hashSecret
+func secretHashSalt(hash string) string {
+	return strings.SplitN(strings.TrimPrefix(hash, secretHashPrefix), ":", 2)[0]
+}
+
+// secretMatchesHash reports whether `secret` is the value that was hashed
+func secretMatchesHash(secret, hash string) bool {
+	if !isSecretHash(hash) {
+		return false
+	}
+	return hashSecret(secret, secretHashSalt(hash)) == hash
+}
+
+// suppressSecretHashDiff is the DiffSuppressFunc of archive credentials. The
+// value in state is a hash, which is compared with the configured secret.
+func suppressSecretHashDiff(k, old, new string, d *schema.ResourceData) bool {
+	return old == new || secretMatchesHash(new, old)
+}
+
+// archiveSecret returns the configured value of a credential. The value comes
+// from the raw configuration, since the planned value is the hash in state
+// whenever the credential did not change.
+func archiveSecret(d *schema.ResourceData, integration, field string) (string, error) {
+	configKey := fmt.Sprintf("%s_config", integration)
+	if value, ok := rawConfigString(d, configKey, field); ok {
+		return value, nil
+	}
+
+	value := d.Get(fmt.Sprintf("%s.0.%s", configKey, field)).(string)
+	if isSecretHash(value) {
+		return "", fmt.Errorf("%s.%s is only known as a hash, it must be set in the configuration", configKey, field)
+	}
+	return value, nil
+}
+
+func rawConfigString(d *schema.ResourceData, block, field string) (string, bool) {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() || !raw.Type().HasAttribute(block) {
+		return "", false
+	}
+	list := raw.GetAttr(block)
+	if list.IsNull() || !list.IsKnown() || list.LengthInt() == 0 {
+		return "", false
+	}
+	elem := list.AsValueSlice()[0]
+	if elem.IsNull() || !elem.Type().IsObjectType() || !elem.Type().HasAttribute(field) {
+		return "", false
+	}
+	value := elem.GetAttr(field)
+	if value.IsNull() || !value.IsKnown() {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// setArchiveSecretHashes replaces the credentials that were just sent to the
+// API with their hash in state
+func setArchiveSecretHashes(d *schema.ResourceData, integration string, diags *diag.Diagnostics) {
+	configKey := fmt.Sprintf("%s_config", integration)
+	configRaw := d.Get(configKey).([]interface{})
+	if len(configRaw) == 0 || configRaw[0] == nil {
+		return
+	}
+
+	config := make(map[string]interface{})
+	for k, v := range configRaw[0].(map[string]interface{}) {
+		config[k] = v
+	}
+	for _, field := range archiveSecretFields[integration] {
+		secret, err := archiveSecret(d, integration, field)
+		if err != nil {
+			appendError(err, diags)
+			return
+		}
+		config[field] = newSecretHash(secret)
+	}
+	appendError(d.Set(configKey, []interface{}{config}), diags)
+}
+
+// archiveSecretState returns the value to store in state for a credential
+// returned by the API. Masked or missing values keep the hash in state. A
+// clear value that does not match the hash is hashed again with the same salt,
+// so that refreshing the same value again leaves the state as it is. It shows
+// as a diff if it does not match the configuration either.
+func archiveSecretState(current, remote string) string {
+	// States written before credentials were hashed hold them in clear
+	if current != "" && !isSecretHash(current) {
+		current = newSecretHash(current)
+	}
+	if remote == "" || isMaskedSecret(remote) || secretMatchesHash(remote, current) {
+		return current
+	}
+	if !isSecretHash(current) {
+		return newSecretHash(remote)
+	}
+	return hashSecret(remote, secretHashSalt(current))
+}
@@ -0,0 +1,128 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveSecrets_hash(t *testing.T) {
+	assert := assert.New(t)
+
+	hash := newSecretHash("s3cr3t")
+	assert.True(isSecretHash(hash))
+	assert.False(strings.Contains(hash, "s3cr3t"), "The secret is not in the hash")
+	assert.True(secretMatchesHash("s3cr3t", hash))
+	assert.False(secretMatchesHash("other", hash))
+	assert.NotEqual(hash, newSecretHash("s3cr3t"), "Every hash has its own salt")
+	assert.Equal(hashSecret("s3cr3t", "abc"), hashSecret("s3cr3t", "abc"))
+
+	assert.False(isSecretHash("s3cr3t"))
+	assert.False(secretMatchesHash("s3cr3t", "s3cr3t"), "Clear values are not hashes")
+}
+
+func TestArchiveSecrets_archiveSecretState(t *testing.T) {
+	assert := assert.New(t)
+	hash := newSecretHash("s3cr3t")
+
+	assert.Equal(hash, archiveSecretState(hash, "****3t"), "Masks are never drift")
+	assert.Equal(hash, archiveSecretState(hash, ""), "Missing values are never drift")
+	assert.Equal(hash, archiveSecretState(hash, "s3cr3t"), "The same clear value keeps the hash")
+	changed := archiveSecretState(hash, "changed")
+	assert.True(secretMatchesHash("changed", changed), "Other clear values are hashed")
+	assert.Equal(secretHashSalt(hash), secretHashSalt(changed), "The salt is kept")
+	assert.Equal(changed, archiveSecretState(changed, "changed"), "Refreshing the same value again does not change the state")
+	assert.Equal(changed, archiveSecretState(hash, "changed"), "The same change is hashed the same way on every refresh")
+	assert.True(secretMatchesHash("new", archiveSecretState("", "new")), "Values without a hash in state are hashed")
+	assert.Equal("", archiveSecretState("", "****3t"), "Nothing is stored on import")
+
+	migrated := archiveSecretState("s3cr3t", "****3t")
+	assert.True(secretMatchesHash("s3cr3t", migrated), "Clear values in state are hashed")
+}
+
+func TestArchiveSecrets_diff(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceArchiveConfig()
+
+	state := &terraform.InstanceState{
+		ID: archiveConfigID,
+		Attributes: map[string]string{
+			"id":                     archiveConfigID,
+			"integration":            "dos",
+			"verify":                 "false",
+			"dos_config.#":           "1",
+			"dos_config.0.space":     "logs",
+			"dos_config.0.endpoint":  "nyc3.digitaloceanspaces.com",
+			"dos_config.0.accesskey": newSecretHash("access"),
+			"dos_config.0.secretkey": newSecretHash("secret"),
+		},
+	}
+	config := func(secretkey string) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"integration": "dos",
+			"dos_config": []interface{}{map[string]interface{}{
+				"space":     "logs",
+				"endpoint":  "nyc3.digitaloceanspaces.com",
+				"accesskey": "access",
+				"secretkey": secretkey,
+			}},
+		})
+	}
+
+	diff, err := r.Diff(context.Background(), state, config("secret"), nil)
+	assert.Nil(err, "No errors")
+	assert.Nil(diff, "Unchanged credentials match their hash")
+
+	diff, err = r.Diff(context.Background(), state, config("rotated"), nil)
+	assert.Nil(err, "No errors")
+	assert.NotNil(diff, "Changed credentials are a diff")
+	assert.Contains(diff.Attributes, "dos_config.0.secretkey")
+	assert.NotContains(diff.Attributes, "dos_config.0.accesskey")
+	assert.True(diff.Attributes["dos_config.0.secretkey"].Sensitive, "Credentials are sensitive")
+}
+
+func TestArchiveSecrets_apply(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			assert.Nil(json.NewDecoder(r.Body).Decode(&sent))
+		}
+		fmt.Fprint(w, `{"integration": "ibm", "bucket": "logs", "endpoint": "s3.example.com", "apikey": "****2345", "resourceinstanceid": "crn"}`)
+	}))
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	d := schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, map[string]interface{}{
+		"integration": "ibm",
+		"ibm_config": []interface{}{map[string]interface{}{
+			"bucket":             "logs",
+			"endpoint":           "s3.example.com",
+			"apikey":             "key12345",
+			"resourceinstanceid": "crn",
+		}},
+	})
+	diags := resourceArchiveConfigCreate(context.Background(), d, pc)
+	assert.False(diags.HasError(), "No errors")
+	assert.Equal("key12345", sent["apikey"], "The credential is sent in clear")
+
+	apikey := d.Get("ibm_config.0.apikey").(string)
+	assert.True(secretMatchesHash("key12345", apikey), "Only the hash is stored in state")
+	assert.Equal("logs", d.Get("ibm_config.0.bucket"))
+
+	assert.False(resourceArchiveConfigRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(apikey, d.Get("ibm_config.0.apikey"), "The masked value is not drift")
+
+	// Without a configuration, the hash cannot be sent back
+	_, err := generateArchiveConfig(d)
+	assert.EqualError(err, "ibm_config.apikey is only known as a hash, it must be set in the configuration")
+}
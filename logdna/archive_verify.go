@@ -0,0 +1,64 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Statuses of the archive configuration. LogDNA checks that it can write to
+// the bucket after the configuration is saved.
+const (
+	archiveStatusPending   = "pending"
+	archiveStatusVerifying = "verifying"
+	archiveStatusActive    = "active"
+	archiveStatusFailed    = "failed"
+)
+
+// How often the archive status is polled while it is being verified
+var archivePollInterval = 10 * time.Second
+
+const archiveDefaultTimeout = 10 * time.Minute
+
+func getArchiveConfig(pc *providerConfig) (*archiveResponse, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		"/v1/config/archiving",
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	// The body holds the archive credentials, it is never logged
+	log.Printf("[DEBUG] GET archive config, %d bytes received", len(body))
+	if err != nil {
+		return nil, err
+	}
+
+	c := archiveResponse{}
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// waitForArchiveVerification polls the archive until its configuration is
+// verified, or fails
+func waitForArchiveVerification(ctx context.Context, pc *providerConfig, timeout time.Duration) error {
+	poller := statusPoller{
+		operation: "archive verification",
+		pending:   []string{archiveStatusPending, archiveStatusVerifying},
+		target:    archiveStatusActive,
+		failed:    archiveStatusFailed,
+		interval:  archivePollInterval,
+		fetch: func() (string, string, error) {
+			c, err := getArchiveConfig(pc)
+			if err != nil {
+				return "", "", err
+			}
+			return c.Status, c.LastError, nil
+		},
+	}
+	return poller.wait(ctx, timeout)
+}
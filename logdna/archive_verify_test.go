@@ -0,0 +1,86 @@
+package logdna
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// testArchiveStatusServer returns the archive with each of the statuses in
+// turn, one per GET, and then keeps returning the last one
+func testArchiveStatusServer(statuses ...string) (*httptest.Server, *int) {
+	return testStatusServer(
+		`{"integration": "s3", "bucket": "logs", "status": %q, "lasterror": %q}`,
+		archiveStatusFailed,
+		"Access Denied: s3:PutObject on logs",
+		statuses...,
+	)
+}
+
+func TestArchiveVerify_waitForArchiveVerification(t *testing.T) {
+	assert := assert.New(t)
+	defer func(interval time.Duration) { archivePollInterval = interval }(archivePollInterval)
+	archivePollInterval = time.Millisecond
+
+	ts, gets := testArchiveStatusServer(archiveStatusPending, archiveStatusVerifying, archiveStatusActive)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	assert.Nil(waitForArchiveVerification(context.Background(), pc, time.Minute), "The archive becomes active")
+	assert.Equal(3, *gets, "Every status is polled")
+
+	ts, _ = testArchiveStatusServer(archiveStatusVerifying, archiveStatusFailed)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	err := waitForArchiveVerification(context.Background(), pc, time.Minute)
+	assert.NotNil(err, "The archive fails")
+	assert.Contains(err.Error(), "archive verification failed: Access Denied: s3:PutObject on logs")
+
+	ts, _ = testArchiveStatusServer(archiveStatusVerifying)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	err = waitForArchiveVerification(context.Background(), pc, 50*time.Millisecond)
+	assert.NotNil(err, "The verification times out")
+	assert.Contains(err.Error(), "timeout while waiting for state to become 'active'")
+
+	ts, gets = testArchiveStatusServer()
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	assert.Nil(waitForArchiveVerification(context.Background(), pc, time.Minute), "No status means nothing to wait for")
+	assert.Equal(1, *gets)
+}
+
+func TestArchiveVerify_Create(t *testing.T) {
+	assert := assert.New(t)
+	defer func(interval time.Duration) { archivePollInterval = interval }(archivePollInterval)
+	archivePollInterval = time.Millisecond
+
+	cfg := map[string]interface{}{
+		"integration": "s3",
+		"verify":      true,
+		"s3_config":   []interface{}{map[string]interface{}{"bucket": "logs"}},
+	}
+
+	ts, _ := testArchiveStatusServer(archiveStatusPending, archiveStatusFailed)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	d := schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, cfg)
+	diags := resourceArchiveConfigCreate(context.Background(), d, pc)
+	assert.True(diags.HasError(), "The apply fails")
+	assert.Equal("The archive configuration could not be verified", diags[0].Summary)
+	assert.Equal(archiveStatusFailed, d.Get("status"))
+	assert.Equal("Access Denied: s3:PutObject on logs", d.Get("last_error"))
+
+	ts, gets := testArchiveStatusServer(archiveStatusActive)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	cfg["verify"] = false
+	d = schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, cfg)
+	assert.False(resourceArchiveConfigCreate(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(1, *gets, "Only Read gets the archive without verify")
+	assert.Equal(archiveStatusActive, d.Get("status"))
+	assert.Equal("", d.Get("last_error"))
+}
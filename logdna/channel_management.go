@@ -0,0 +1,359 @@
+package logdna
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Values of `manage_channels`, which decides what Terraform does with channels
+// that are on the server but not declared in the configuration
+const (
+	// Declared channels are the only channels, others are drift and are removed
+	manageChannelsAuthoritative = "authoritative"
+	// Only declared channels are managed, others are preserved on update
+	manageChannelsAdditive = "additive"
+	// Channels are only sent on create, and never read or updated afterwards
+	manageChannelsIgnore = "ignore"
+)
+
+var manageChannelsModes = []string{manageChannelsAuthoritative, manageChannelsAdditive, manageChannelsIgnore}
+
+// Values of `unknown_channels`, which decides what happens to remote channels
+// whose integration is not modeled and that are not declared as `raw_channel`
+const (
+	unknownChannelsKeep   = "keep"
+	unknownChannelsRemove = "remove"
+)
+
+var unknownChannelsModes = []string{unknownChannelsKeep, unknownChannelsRemove}
+
+var validateManageChannels = validateOneOf(manageChannelsModes)
+
+// getRemoteChannels returns the channels of a view or preset alert as they are
+// on the server
+func getRemoteChannels(pc *providerConfig, uri string) ([]channelResponse, error) {
+	req := newRequestConfig(pc, "GET", uri, nil)
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] GET %s raw response body %s\n", uri, body)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := struct {
+		Channels []channelResponse `json:"channels"`
+	}{}
+	if err := json.Unmarshal(body, &remote); err != nil {
+		return nil, err
+	}
+	return remote.Channels, nil
+}
+
+// channelToSchema maps a single remote channel to its integration and schema
+// value, using the same mappers as Read
+func channelToSchema(c channelResponse) (string, map[string]interface{}) {
+	channels := []channelResponse{c}
+	integrations, _ := mapAllChannelsToSchema("channel", &channels)
+	for integration, list := range integrations {
+		if len(list) > 0 {
+			return integration, list[0].(map[string]interface{})
+		}
+	}
+	return "", nil
+}
+
+// channelRequestFromResponse builds the request for a remote channel with the
+// same mappers as the declared channels, since the API does not accept channels
+// in the shape it returns them. Masked secrets are replaced with the ones
+// Terraform sent, and a channel whose secrets cannot be recovered is not sent
+// back: the mask would replace the secret.
+func channelRequestFromResponse(d *schema.ResourceData, c channelResponse, diags *diag.Diagnostics) (channelRequest, bool) {
+	integration, value := channelToSchema(c)
+	if value != nil {
+		value = unmaskKnownChannel(d, integration, value)
+	}
+	if value == nil {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot preserve a channel that is not managed by Terraform",
+			Detail:   fmt.Sprintf("The %s channel cannot be mapped to a request", c.Integration),
+		})
+		return channelRequest{}, false
+	}
+
+	masked := channelHasMaskedSecret(value, channelSecretFields[integration])
+	if integration == RAW {
+		masked = hasMaskedValue(c.raw)
+	}
+	if masked {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot preserve a channel that is not managed by Terraform",
+			Detail: fmt.Sprintf(
+				"The %s channel (operator %q) has a masked secret, which would replace the real secret if it was sent back. "+
+					"Declare the channel in the configuration, or set manage_channels to %q to remove it.",
+				c.Integration, c.Operator, manageChannelsAuthoritative,
+			),
+		})
+		return channelRequest{}, false
+	}
+
+	// The GET shape of the fields differs from the schema: emails may be a list,
+	// trigger intervals numbers, and headers are not generic maps
+	if emails, ok := value["emails"]; ok {
+		value["emails"] = stringsToList(toStringList(emails))
+	}
+	if ti, ok := value["triggerinterval"]; ok {
+		value["triggerinterval"] = ""
+		if ti != nil {
+			value["triggerinterval"] = fmt.Sprintf("%v", ti)
+		}
+	}
+	if headers, ok := value["headers"].(map[string]string); ok {
+		generic := make(map[string]interface{}, len(headers))
+		for k, v := range headers {
+			generic[k] = v
+		}
+		value["headers"] = generic
+	}
+
+	requests := *iterateIntegrationType([]interface{}{value}, integration, diags)
+	if len(requests) == 0 {
+		return channelRequest{}, false
+	}
+	return requests[0], true
+}
+
+// hasMaskedValue reports whether any string in a decoded JSON value is masked
+func hasMaskedValue(v interface{}) bool {
+	switch value := v.(type) {
+	case string:
+		return isMaskedSecret(value)
+	case map[string]interface{}:
+		for _, elem := range value {
+			if hasMaskedValue(elem) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, elem := range value {
+			if hasMaskedValue(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// manageChannelsSchema is the `manage_channels` attribute of views and alerts
+var manageChannelsSchema = &schema.Schema{
+	Type:         schema.TypeString,
+	Optional:     true,
+	Default:      manageChannelsAuthoritative,
+	ValidateFunc: validateManageChannels,
+}
+
+// unknownChannelsSchema is the `unknown_channels` attribute of views and alerts
+var unknownChannelsSchema = &schema.Schema{
+	Type:         schema.TypeString,
+	Optional:     true,
+	Default:      unknownChannelsKeep,
+	ValidateFunc: validateOneOf(unknownChannelsModes),
+}
+
+// rawChannelSchema is the `raw_channel` block of views and alerts, which holds
+// channels of the integrations that have no block of their own
+var rawChannelSchema = &schema.Schema{
+	Type:             schema.TypeList,
+	Optional:         true,
+	DiffSuppressFunc: suppressRawChannelDiff,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"integration": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateRawChannelIntegration,
+			},
+			"settings": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Sensitive:    true,
+				ValidateFunc: validateJSONObject,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return webhookBodiesEqual(bodyFormatJSON, old, new)
+				},
+			},
+		},
+	},
+}
+
+func validateRawChannelIntegration(val interface{}, key string) (warns []string, errs []error) {
+	if v := val.(string); isModeledIntegration(v) {
+		errs = append(errs, fmt.Errorf("%q must not be a modeled integration, use %s_channel instead", key, v))
+	}
+	return
+}
+
+func validateJSONObject(val interface{}, key string) (warns []string, errs []error) {
+	var settings map[string]interface{}
+	if err := json.Unmarshal([]byte(val.(string)), &settings); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a JSON object: %s", key, err))
+	}
+	return
+}
+
+// preservedChannels returns the remote channels to send back on update, on top
+// of the declared channels. In `additive` mode, these are the channels of the
+// modeled integrations that are neither declared nor were previously managed
+// by Terraform. Channels of other integrations are left to `unknown_channels`.
+// In `ignore` mode, every remote channel is sent back.
+func preservedChannels(mode string, d *schema.ResourceData, remote []channelResponse) ([]channelRequest, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	preserved := make([]channelRequest, 0)
+	if mode == manageChannelsAuthoritative {
+		return preserved, diags
+	}
+
+	for _, c := range remote {
+		if mode == manageChannelsAdditive {
+			if !isModeledIntegration(c.Integration) || isManagedChannel(d, c) {
+				continue
+			}
+		}
+		if request, ok := channelRequestFromResponse(d, c, &diags); ok {
+			preserved = append(preserved, request)
+		}
+	}
+	return preserved, diags
+}
+
+// mergeRemoteChannels adds the remote channels that Terraform does not manage
+// to the channels about to be sent to `uri`
+func mergeRemoteChannels(pc *providerConfig, d *schema.ResourceData, uri string, channels *[]channelRequest) diag.Diagnostics {
+	mode := d.Get("manage_channels").(string)
+	if mode == manageChannelsAuthoritative {
+		return nil
+	}
+
+	remote, err := getRemoteChannels(pc, uri)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if mode == manageChannelsIgnore {
+		*channels = make([]channelRequest, 0, len(remote))
+	}
+	preserved, diags := preservedChannels(mode, d, remote)
+	*channels = append(*channels, preserved...)
+	return diags
+}
+
+// isModeledIntegration reports whether an integration has its own channel block
+func isModeledIntegration(integration string) bool {
+	switch integration {
+	case EMAIL, PAGERDUTY, SLACK, WEBHOOK:
+		return true
+	}
+	return false
+}
+
+// ignoresChannels reports whether the channel blocks of an existing resource
+// are ignored, in which case they are never a diff
+func ignoresChannels(d *schema.ResourceData) bool {
+	mode, _ := d.Get("manage_channels").(string)
+	return mode == manageChannelsIgnore && d.Id() != ""
+}
+
+// knownChannels returns the channels of an integration in state and in the
+// configuration
+func knownChannels(d *schema.ResourceData, integration string) []interface{} {
+	old, new := d.GetChange(fmt.Sprintf("%s_channel", integration))
+	return append(append([]interface{}{}, old.([]interface{})...), new.([]interface{})...)
+}
+
+// unmaskKnownChannel replaces the masked secrets of a remote channel with the
+// secrets Terraform sent for it, if it knows them
+func unmaskKnownChannel(d *schema.ResourceData, integration string, value map[string]interface{}) map[string]interface{} {
+	if _, ok := channelSecretFields[integration]; !ok {
+		return value
+	}
+	return unmaskChannels(integration, knownChannels(d, integration), []interface{}{value}, getChannelSecretHashes(d))[0].(map[string]interface{})
+}
+
+func isManagedChannel(d *schema.ResourceData, c channelResponse) bool {
+	integration, value := channelToSchema(c)
+	if value == nil {
+		return false
+	}
+
+	// Masked secrets are compared with the values known to Terraform
+	managed := knownChannels(d, integration)
+	value = unmaskKnownChannel(d, integration, value)
+	identity := channelIdentity(integration, value)
+	for _, m := range managed {
+		if channelIdentity(integration, m.(map[string]interface{})) == identity {
+			return true
+		}
+	}
+	return false
+}
+
+// managedRemoteChannels filters the remote channels of an integration down to
+// the ones managed by Terraform, i.e. the ones matching a channel in `current`
+func managedRemoteChannels(integration string, current []interface{}, remote []interface{}) []interface{} {
+	identities := make(map[string]bool, len(current))
+	for _, c := range current {
+		if cm, ok := c.(map[string]interface{}); ok {
+			identities[channelIdentity(integration, cm)] = true
+		}
+	}
+
+	managed := make([]interface{}, 0, len(remote))
+	for _, r := range remote {
+		if identities[channelIdentity(integration, r.(map[string]interface{}))] {
+			managed = append(managed, r)
+		}
+	}
+	return managed
+}
+
+// setChannelsSchema stores the remote channels in the schema according to
+// `manage_channels`. Every block is set, which removes the integrations that
+// are not on the server anymore.
+func setChannelsSchema(d *schema.ResourceData, integrations map[string][]interface{}, diags *diag.Diagnostics) {
+	mode, _ := d.Get("manage_channels").(string)
+	if mode == manageChannelsIgnore {
+		return
+	}
+
+	hashes := getChannelSecretHashes(d)
+	for integration, value := range integrations {
+		schemaKey := fmt.Sprintf("%s_channel", integration)
+		current := d.Get(schemaKey).([]interface{})
+		// Masked secrets are only drift when they do not match what was sent
+		value = unmaskChannels(integration, current, value, hashes)
+		if integration == WEBHOOK {
+			value = keepWebhookBodyFormats(current, value)
+		}
+		if mode == manageChannelsAdditive {
+			value = managedRemoteChannels(integration, current, value)
+		}
+		// Keep the order already in state so that an API reorder is not reported as drift
+		value = orderChannelsLike(integration, current, value)
+		appendError(d.Set(schemaKey, value), diags)
+	}
+}
+
+// setImportDefaults sets the channel management options to their default on
+// import, since they only exist in Terraform
+func setImportDefaults(d *schema.ResourceData) {
+	if err := d.Set("manage_channels", manageChannelsAuthoritative); err != nil {
+		log.Printf("[WARN] Cannot set manage_channels on import: %s", err)
+	}
+	if err := d.Set("unknown_channels", unknownChannelsKeep); err != nil {
+		log.Printf("[WARN] Cannot set unknown_channels on import: %s", err)
+	}
+}
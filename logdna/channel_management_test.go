@@ -0,0 +1,369 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func slackConfig(url string) map[string]interface{} {
+	return map[string]interface{}{
+		"url":             url,
+		"operator":        "presence",
+		"triggerlimit":    15,
+		"triggerinterval": "30",
+	}
+}
+
+func TestChannelManagement_validateManageChannels(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, mode := range manageChannelsModes {
+		_, errs := validateManageChannels(mode, "manage_channels")
+		assert.Empty(errs, mode)
+	}
+
+	_, errs := validateManageChannels("partial", "manage_channels")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.EqualError(errs[0], `"manage_channels" must be one of [authoritative additive ignore], got: partial`)
+}
+
+func TestChannelManagement_channelSchemas(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, r := range []*schema.Resource{resourceView(), resourceAlert()} {
+		assert.Same(rawChannelSchema, r.Schema["raw_channel"])
+		assert.Same(unknownChannelsSchema, r.Schema["unknown_channels"])
+	}
+
+	_, errs := unknownChannelsSchema.ValidateFunc("drop", "unknown_channels")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.EqualError(errs[0], `"unknown_channels" must be one of [keep remove], got: drop`)
+
+	_, errs = validateRawChannelIntegration("opsgenie", "integration")
+	assert.Empty(errs)
+	_, errs = validateRawChannelIntegration(SLACK, "integration")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.EqualError(errs[0], `"integration" must not be a modeled integration, use slack_channel instead`)
+
+	_, errs = validateJSONObject(`{"priority": "P1"}`, "settings")
+	assert.Empty(errs)
+	_, errs = validateJSONObject(`["P1"]`, "settings")
+	assert.Len(errs, 1, "There was 1 error")
+}
+
+func TestChannelManagement_setChannelsSchema(t *testing.T) {
+	assert := assert.New(t)
+	remote := func() map[string][]interface{} {
+		return map[string][]interface{}{
+			SLACK: {
+				map[string]interface{}{"url": "https://hooks.slack.com/incident", "operator": "presence"},
+				map[string]interface{}{"url": "https://hooks.slack.com/team", "operator": "presence"},
+			},
+			EMAIL: {},
+		}
+	}
+	dataFor := func(mode string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceView().Schema, map[string]interface{}{
+			"name":            "test",
+			"manage_channels": mode,
+			"slack_channel":   []interface{}{slackConfig("https://hooks.slack.com/team")},
+		})
+	}
+
+	t.Run("Authoritative reports every remote channel", func(t *testing.T) {
+		var diags diag.Diagnostics
+		d := dataFor(manageChannelsAuthoritative)
+		setChannelsSchema(d, remote(), &diags)
+		assert.Empty(diags, "No errors")
+		assert.Equal(2, d.Get("slack_channel.#"))
+		assert.Equal("https://hooks.slack.com/team", d.Get("slack_channel.0.url"), "The order of the state is kept")
+	})
+
+	t.Run("Additive only reports the declared channels", func(t *testing.T) {
+		var diags diag.Diagnostics
+		d := dataFor(manageChannelsAdditive)
+		setChannelsSchema(d, remote(), &diags)
+		assert.Empty(diags, "No errors")
+		assert.Equal(1, d.Get("slack_channel.#"))
+		assert.Equal("https://hooks.slack.com/team", d.Get("slack_channel.0.url"))
+	})
+
+	t.Run("Ignore does not read the channels", func(t *testing.T) {
+		var diags diag.Diagnostics
+		d := dataFor(manageChannelsIgnore)
+		setChannelsSchema(d, map[string][]interface{}{SLACK: {}}, &diags)
+		assert.Empty(diags, "No errors")
+		assert.Equal(1, d.Get("slack_channel.#"), "The channels are left untouched")
+	})
+}
+
+func TestChannelManagement_mergeRemoteChannels(t *testing.T) {
+	assert := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"viewID": "abc123", "channels": [
+			{"integration": "slack", "url": "https://hooks.slack.com/team", "operator": "presence", "triggerlimit": 15, "alertid": "1"},
+			{"integration": "slack", "url": "https://hooks.slack.com/old", "operator": "presence", "triggerlimit": 15, "alertid": "2"},
+			{"integration": "slack", "url": "https://hooks.slack.com/incident", "operator": "presence", "triggerlimit": 1, "alertid": "3"},
+			{"integration": "opsgenie", "operator": "presence", "priority": "P1", "alertid": "4"}
+		]}`)
+	}))
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	r := resourceView()
+	state := &terraform.InstanceState{
+		ID: "abc123",
+		Attributes: map[string]string{
+			"id":                              "abc123",
+			"name":                            "test",
+			"unknown_channels":                unknownChannelsKeep,
+			"slack_channel.#":                 "2",
+			"slack_channel.0.url":             "https://hooks.slack.com/team",
+			"slack_channel.0.operator":        "presence",
+			"slack_channel.0.immediate":       "false",
+			"slack_channel.0.terminal":        "true",
+			"slack_channel.0.triggerinterval": "30",
+			"slack_channel.0.triggerlimit":    "15",
+			"slack_channel.1.url":             "https://hooks.slack.com/old",
+			"slack_channel.1.operator":        "presence",
+			"slack_channel.1.immediate":       "false",
+			"slack_channel.1.terminal":        "true",
+			"slack_channel.1.triggerinterval": "30",
+			"slack_channel.1.triggerlimit":    "15",
+		},
+	}
+	channelsFor := func(mode string) string {
+		state.Attributes["manage_channels"] = mode
+		config := map[string]interface{}{
+			"name":            "test",
+			"manage_channels": mode,
+			// The "old" channel is removed from the configuration
+			"slack_channel": []interface{}{slackConfig("https://hooks.slack.com/team")},
+		}
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+		assert.Nil(err, "No errors")
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(err, "No errors")
+
+		view := viewRequest{}
+		assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+		assert.Nil(mergeRemoteChannels(pc, d, "/v1/config/view/abc123", &view.Channels), "No errors")
+
+		urls := make([]string, 0, len(view.Channels))
+		for _, c := range view.Channels {
+			encoded, err := json.Marshal(c)
+			assert.Nil(err, "No errors")
+			decoded := map[string]interface{}{}
+			assert.Nil(json.Unmarshal(encoded, &decoded), "No errors")
+			if url, ok := decoded["url"].(string); ok {
+				urls = append(urls, url)
+			} else {
+				urls = append(urls, decoded["integration"].(string))
+			}
+		}
+		return fmt.Sprint(urls)
+	}
+
+	assert.Equal(
+		"[https://hooks.slack.com/team]",
+		channelsFor(manageChannelsAuthoritative),
+		"Only the declared channels are sent",
+	)
+	assert.Equal(
+		"[https://hooks.slack.com/team https://hooks.slack.com/incident]",
+		channelsFor(manageChannelsAdditive),
+		"Channels added outside of Terraform are preserved, removed ones are deleted, and unknown_channels decides for other integrations",
+	)
+	assert.Equal(
+		"[https://hooks.slack.com/team https://hooks.slack.com/old https://hooks.slack.com/incident opsgenie]",
+		channelsFor(manageChannelsIgnore),
+		"The remote channels are sent as-is",
+	)
+}
+
+func TestChannelManagement_mergeMaskedChannels(t *testing.T) {
+	assert := assert.New(t)
+	body := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	// The secret of the declared channel was sent by Terraform
+	hash := hashSecret(channelSecret(SLACK, slackConfig("https://hooks.slack.com/team")), "salt")
+	r := resourceView()
+	state := &terraform.InstanceState{
+		ID: "abc123",
+		Attributes: map[string]string{
+			"id":                      "abc123",
+			"name":                    "test",
+			"unknown_channels":        unknownChannelsKeep,
+			"channel_secret_hashes.#": "1",
+			fmt.Sprintf("channel_secret_hashes.%d", schema.HashString(hash)): hash,
+			"slack_channel.#":                 "1",
+			"slack_channel.0.url":             "https://hooks.slack.com/team",
+			"slack_channel.0.operator":        "presence",
+			"slack_channel.0.immediate":       "false",
+			"slack_channel.0.terminal":        "true",
+			"slack_channel.0.triggerinterval": "30",
+			"slack_channel.0.triggerlimit":    "15",
+		},
+	}
+	merge := func(mode string) ([]channelRequest, diag.Diagnostics) {
+		state.Attributes["manage_channels"] = mode
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":            "test",
+			"manage_channels": mode,
+			"slack_channel":   []interface{}{slackConfig("https://hooks.slack.com/team")},
+		}), nil)
+		assert.Nil(err, "No errors")
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(err, "No errors")
+		assert.Equal([]string{hash}, getChannelSecretHashes(d))
+
+		view := viewRequest{}
+		assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+		diags := mergeRemoteChannels(pc, d, "/v1/config/view/abc123", &view.Channels)
+		return view.Channels, diags
+	}
+
+	body = `{"viewID": "abc123", "channels": [
+		{"integration": "slack", "url": "****team", "operator": "presence", "triggerlimit": 15, "alertid": "1"}
+	]}`
+	channels, diags := merge(manageChannelsIgnore)
+	assert.Empty(diags, "No errors")
+	assert.Len(channels, 1)
+	assert.Equal("https://hooks.slack.com/team", channels[0].URL, "The secret sent by Terraform is sent back")
+
+	// A channel added in the web app has a secret Terraform never knew
+	body = `{"viewID": "abc123", "channels": [
+		{"integration": "slack", "url": "****team", "operator": "presence", "triggerlimit": 15, "alertid": "1"},
+		{"integration": "slack", "url": "****ui", "operator": "presence", "triggerlimit": 1, "alertid": "2"}
+	]}`
+	channels, diags = merge(manageChannelsAdditive)
+	assert.Len(diags, 1)
+	assert.Contains(diags[0].Detail, "has a masked secret")
+	assert.Len(channels, 1, "Only the declared channel is sent")
+
+	channels, diags = merge(manageChannelsIgnore)
+	assert.Len(diags, 1, "Only the channel added in the web app is refused")
+	assert.Len(channels, 1)
+	assert.Equal("https://hooks.slack.com/team", channels[0].URL)
+}
+
+func TestChannelManagement_channelRequestFromResponse(t *testing.T) {
+	assert := assert.New(t)
+	requestFor := func(body string) (map[string]interface{}, diag.Diagnostics) {
+		var diags diag.Diagnostics
+		c := channelResponse{}
+		assert.Nil(json.Unmarshal([]byte(body), &c), "No errors")
+
+		d := schema.TestResourceDataRaw(t, resourceView().Schema, map[string]interface{}{})
+		request, ok := channelRequestFromResponse(d, c, &diags)
+		if !ok {
+			return nil, diags
+		}
+		encoded, err := json.Marshal(request)
+		assert.Nil(err, "No errors")
+		decoded := map[string]interface{}{}
+		assert.Nil(json.Unmarshal(encoded, &decoded), "No errors")
+		return decoded, diags
+	}
+
+	t.Run("Channels are sent in the shape of a request", func(t *testing.T) {
+		request, diags := requestFor(`{
+			"integration": "email", "emails": ["oncall@logdna.com"], "immediate": true, "terminal": true,
+			"operator": "presence", "triggerinterval": 30, "triggerlimit": 1, "alertid": "1"
+		}`)
+		assert.Empty(diags, "No errors")
+		assert.Equal(map[string]interface{}{
+			"integration":     "email",
+			"emails":          []interface{}{"oncall@logdna.com"},
+			"immediate":       "true",
+			"terminal":        "true",
+			"operator":        "presence",
+			"triggerinterval": "30",
+			"triggerlimit":    float64(1),
+		}, request)
+	})
+
+	t.Run("Webhook headers and bodies are mapped", func(t *testing.T) {
+		request, diags := requestFor(`{
+			"integration": "webhook", "url": "https://yourwebhook/endpoint", "method": "post",
+			"headers": {"Accept": "application/json"}, "bodyTemplate": {"text": "{{ name }}"},
+			"operator": "presence", "triggerinterval": "30", "triggerlimit": 1
+		}`)
+		assert.Empty(diags, "No errors")
+		assert.Equal(map[string]interface{}{"Accept": "application/json"}, request["headers"])
+		assert.Equal(map[string]interface{}{"text": "{{ name }}"}, request["bodyTemplate"])
+	})
+
+	t.Run("Channels with masked secrets are not sent back", func(t *testing.T) {
+		for _, body := range []string{
+			`{"integration": "pagerduty", "key": "****1234", "operator": "presence", "triggerlimit": 1}`,
+			`{"integration": "webhook", "url": "https://yourwebhook/endpoint", "headers": {"Authorization": "****"}, "operator": "presence"}`,
+			`{"integration": "opsgenie", "apikey": "****abcd", "operator": "presence"}`,
+		} {
+			request, diags := requestFor(body)
+			assert.Nil(request, body)
+			assert.Len(diags, 1, body)
+			assert.Contains(diags[0].Detail, "has a masked secret", body)
+		}
+	})
+
+	t.Run("Unmodeled integrations keep their settings", func(t *testing.T) {
+		request, diags := requestFor(`{"integration": "opsgenie", "priority": "P1", "operator": "presence", "alertid": "4"}`)
+		assert.Empty(diags, "No errors")
+		assert.Equal(map[string]interface{}{"integration": "opsgenie", "priority": "P1", "operator": "presence"}, request)
+	})
+}
+
+func TestChannelManagement_ignoreSuppressesChannelDiffs(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceView()
+	state := &terraform.InstanceState{
+		ID: "abc123",
+		Attributes: map[string]string{
+			"id":                              "abc123",
+			"name":                            "test",
+			"unknown_channels":                unknownChannelsKeep,
+			"manage_channels":                 manageChannelsIgnore,
+			"channel_secret_hashes.#":         "0",
+			"slack_channel.#":                 "1",
+			"slack_channel.0.url":             "https://hooks.slack.com/team",
+			"slack_channel.0.operator":        "presence",
+			"slack_channel.0.immediate":       "false",
+			"slack_channel.0.terminal":        "true",
+			"slack_channel.0.triggerinterval": "30",
+			"slack_channel.0.triggerlimit":    "15",
+		},
+	}
+	diffFor := func(mode string, slack []interface{}) *terraform.InstanceDiff {
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":            "test",
+			"manage_channels": mode,
+			"slack_channel":   slack,
+		}), nil)
+		assert.Nil(err, "No errors")
+		return diff
+	}
+
+	diff := diffFor(manageChannelsIgnore, []interface{}{slackConfig("https://hooks.slack.com/other")})
+	assert.True(diff == nil || diff.Empty(), "Changed channels are not a diff")
+
+	diff = diffFor(manageChannelsIgnore, []interface{}{})
+	assert.True(diff == nil || diff.Empty(), "Removed channels are not a diff")
+
+	diff = diffFor(manageChannelsAuthoritative, []interface{}{slackConfig("https://hooks.slack.com/other")})
+	assert.Equal("https://hooks.slack.com/other", diff.Attributes["slack_channel.0.url"].New, "Channels are a diff once managed")
+}
@@ -0,0 +1,222 @@
+package logdna
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Channel blocks are lists, but the API does not guarantee the order in which
+// channels are returned, nor does the order carry any meaning. These helpers
+// match channels by a stable identity so that a pure reorder (either in HCL or
+// on the server) does not produce a plan.
+
+// channelIdentity returns the key used to match a channel between state and
+// the remote: integration + destination (emails/url/key) + operator
+func channelIdentity(integration string, c map[string]interface{}) string {
+	var destination string
+	switch integration {
+	case EMAIL:
+		emails := toStringList(c["emails"])
+		sort.Strings(emails)
+		destination = strings.Join(emails, ",")
+	case PAGERDUTY:
+		destination, _ = c["key"].(string)
+	case SLACK, WEBHOOK:
+		destination, _ = c["url"].(string)
+	case RAW:
+		destination, _ = c["integration"].(string)
+	}
+	operator, _ := c["operator"].(string)
+
+	return fmt.Sprintf("%s|%s|%s", integration, destination, operator)
+}
+
+// channelFingerprint returns a canonical representation of every field in the
+// channel, so that two channels with the same fingerprint are interchangeable
+func channelFingerprint(integration string, c map[string]interface{}) string {
+	canonical := make(map[string]interface{}, len(c))
+	for k, v := range c {
+		canonical[k] = v
+	}
+	if emails, ok := c["emails"]; ok {
+		canonical["emails"] = toStringList(emails)
+	}
+	for _, key := range []string{"bodytemplate", "settings"} {
+		if raw, ok := c[key].(string); ok && raw != "" {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(raw), &parsed); err == nil {
+				canonical[key] = parsed
+			}
+		}
+	}
+	if ti, ok := c["triggerinterval"]; ok && ti != nil {
+		canonical["triggerinterval"] = fmt.Sprintf("%v", ti)
+	}
+
+	// json.Marshal sorts map keys, which makes the output stable
+	encoded, err := json.Marshal(canonical)
+	if err != nil {
+		return channelIdentity(integration, c)
+	}
+	return fmt.Sprintf("%s|%s", integration, encoded)
+}
+
+// orderChannelsLike sorts the remote channels of a single integration so they
+// follow the order of the matching channels in `current` (usually the state).
+// Exact matches are paired first, then channels with the same identity.
+// Remote channels without a counterpart keep their relative order at the end.
+func orderChannelsLike(integration string, current []interface{}, remote []interface{}) []interface{} {
+	if len(current) == 0 || len(remote) < 2 {
+		return remote
+	}
+
+	used := make([]bool, len(remote))
+	slots := make([]interface{}, len(current))
+
+	pair := func(key func(string, map[string]interface{}) string) {
+		for i, c := range current {
+			if slots[i] != nil {
+				continue
+			}
+			cm, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			want := key(integration, cm)
+			for j, r := range remote {
+				if used[j] {
+					continue
+				}
+				if key(integration, r.(map[string]interface{})) == want {
+					slots[i] = r
+					used[j] = true
+					break
+				}
+			}
+		}
+	}
+	pair(channelFingerprint)
+	pair(channelIdentity)
+
+	ordered := make([]interface{}, 0, len(remote))
+	for _, s := range slots {
+		if s != nil {
+			ordered = append(ordered, s)
+		}
+	}
+	for j, r := range remote {
+		if !used[j] {
+			ordered = append(ordered, r)
+		}
+	}
+	return ordered
+}
+
+// isChannelReorder reports whether both lists contain exactly the same
+// channels, regardless of their order
+func isChannelReorder(integration string, old []interface{}, new []interface{}) bool {
+	return len(old) == len(new) && isChannelSubset(integration, old, new)
+}
+
+// isChannelSubset reports whether every channel in `new` is also in `old`
+func isChannelSubset(integration string, old []interface{}, new []interface{}) bool {
+	counts := make(map[string]int, len(old))
+	for _, o := range old {
+		if om, ok := o.(map[string]interface{}); ok {
+			counts[channelFingerprint(integration, om)]++
+		}
+	}
+	for _, n := range new {
+		nm, ok := n.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		fp := channelFingerprint(integration, nm)
+		if counts[fp] == 0 {
+			return false
+		}
+		counts[fp]--
+	}
+	return true
+}
+
+// suppressRawChannelDiff is the DiffSuppressFunc for `raw_channel`. On top of
+// reorders, remote channels that are not declared are not a diff when unknown
+// channels are kept.
+func suppressRawChannelDiff(k, old, new string, d *schema.ResourceData) bool {
+	if ignoresChannels(d) {
+		return true
+	}
+	if d.Get("unknown_channels").(string) != unknownChannelsKeep {
+		return suppressChannelReorder(k, old, new, d)
+	}
+
+	o, n := d.GetChange("raw_channel")
+	shouldSuppress := isChannelSubset(RAW, o.([]interface{}), n.([]interface{}))
+	if shouldSuppress {
+		log.Println("[DEBUG] raw_channel only differs by channels that are not declared and kept")
+	}
+	return shouldSuppress
+}
+
+// suppressChannelReorder is a DiffSuppressFunc for the `*_channel` blocks.
+// It is invoked for every nested attribute of the block and suppresses the
+// diff when the whole block is only a permutation of what is in state. The
+// channels of an existing resource are never a diff when they are ignored.
+func suppressChannelReorder(k, old, new string, d *schema.ResourceData) bool {
+	if ignoresChannels(d) {
+		log.Printf("[DEBUG] %s is ignored, manage_channels is %q", k, manageChannelsIgnore)
+		return true
+	}
+
+	// When the whole block is removed from the config, GetChange falls back to the
+	// state for the new value. The list count is the only reliable value then.
+	if strings.HasSuffix(k, ".#") && old != new {
+		return false
+	}
+
+	block := strings.SplitN(k, ".", 2)[0]
+	integration := strings.TrimSuffix(block, "_channel")
+
+	o, n := d.GetChange(block)
+	oldList, ok := o.([]interface{})
+	if !ok {
+		return false
+	}
+	newList, ok := n.([]interface{})
+	if !ok {
+		return false
+	}
+
+	shouldSuppress := isChannelReorder(integration, oldList, newList)
+	if shouldSuppress {
+		log.Printf("[DEBUG] %s only differs by channel order between state and config", block)
+	}
+	return shouldSuppress
+}
+
+func toStringList(v interface{}) []string {
+	strs := make([]string, 0)
+	switch list := v.(type) {
+	case []string:
+		strs = append(strs, list...)
+	case []interface{}:
+		for _, elem := range list {
+			if s, ok := elem.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+	case string:
+		for _, s := range strings.Split(list, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				strs = append(strs, s)
+			}
+		}
+	}
+	return strs
+}
@@ -0,0 +1,189 @@
+package logdna
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func emailChannel(emails []interface{}, operator string, triggerlimit int) map[string]interface{} {
+	return map[string]interface{}{
+		"emails":          emails,
+		"immediate":       "false",
+		"operator":        operator,
+		"terminal":        "true",
+		"timezone":        "Pacific/Samoa",
+		"triggerinterval": "15m",
+		"triggerlimit":    triggerlimit,
+	}
+}
+
+func TestChannelMatching_channelIdentity(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Email identity ignores the order of the addresses and their representation", func(t *testing.T) {
+		fromList := emailChannel([]interface{}{"b@logdna.com", "a@logdna.com"}, "absence", 15)
+		fromString := emailChannel(nil, "absence", 15)
+		fromString["emails"] = "a@logdna.com, b@logdna.com"
+
+		assert.Equal(
+			channelIdentity(EMAIL, fromList),
+			channelIdentity(EMAIL, fromString),
+			"Identities match",
+		)
+	})
+
+	t.Run("Operator is part of the identity", func(t *testing.T) {
+		presence := emailChannel([]interface{}{"a@logdna.com"}, "presence", 15)
+		absence := emailChannel([]interface{}{"a@logdna.com"}, "absence", 15)
+
+		assert.NotEqual(channelIdentity(EMAIL, presence), channelIdentity(EMAIL, absence), "Identities differ")
+	})
+
+	t.Run("URL is the destination for webhooks", func(t *testing.T) {
+		one := map[string]interface{}{"url": "https://one", "operator": "presence"}
+		two := map[string]interface{}{"url": "https://two", "operator": "presence"}
+
+		assert.NotEqual(channelIdentity(WEBHOOK, one), channelIdentity(WEBHOOK, two), "Identities differ")
+	})
+}
+
+func TestChannelMatching_isChannelReorder(t *testing.T) {
+	assert := assert.New(t)
+	first := emailChannel([]interface{}{"first@logdna.com"}, "absence", 15)
+	second := emailChannel([]interface{}{"second@logdna.com"}, "absence", 15)
+
+	t.Run("A permutation of the same channels is a reorder", func(t *testing.T) {
+		assert.True(isChannelReorder(EMAIL, []interface{}{first, second}, []interface{}{second, first}))
+	})
+
+	t.Run("Duplicate channels are counted", func(t *testing.T) {
+		assert.True(isChannelReorder(EMAIL, []interface{}{first, first}, []interface{}{first, first}))
+		assert.False(isChannelReorder(EMAIL, []interface{}{first, first}, []interface{}{first, second}))
+	})
+
+	t.Run("A changed field is not a reorder", func(t *testing.T) {
+		changed := emailChannel([]interface{}{"second@logdna.com"}, "absence", 20)
+		assert.False(isChannelReorder(EMAIL, []interface{}{first, second}, []interface{}{changed, first}))
+	})
+
+	t.Run("Different lengths are not a reorder", func(t *testing.T) {
+		assert.False(isChannelReorder(EMAIL, []interface{}{first, second}, []interface{}{first}))
+	})
+
+	t.Run("Webhook body templates are compared as JSON", func(t *testing.T) {
+		a := map[string]interface{}{"url": "https://a", "bodytemplate": `{"a": 1, "b": 2}`}
+		b := map[string]interface{}{"url": "https://b", "bodytemplate": "{}"}
+		aRemote := map[string]interface{}{"url": "https://a", "bodytemplate": "{\n  \"b\": 2,\n  \"a\": 1\n}"}
+
+		assert.True(isChannelReorder(WEBHOOK, []interface{}{a, b}, []interface{}{b, aRemote}))
+	})
+}
+
+func TestChannelMatching_orderChannelsLike(t *testing.T) {
+	assert := assert.New(t)
+	first := emailChannel([]interface{}{"first@logdna.com"}, "absence", 15)
+	second := emailChannel([]interface{}{"second@logdna.com"}, "absence", 15)
+	third := emailChannel([]interface{}{"third@logdna.com"}, "presence", 15)
+
+	t.Run("Remote channels follow the order in state", func(t *testing.T) {
+		ordered := orderChannelsLike(EMAIL, []interface{}{first, second}, []interface{}{second, first})
+		assert.Equal([]interface{}{first, second}, ordered)
+	})
+
+	t.Run("Channels changed remotely are matched by identity", func(t *testing.T) {
+		changed := emailChannel([]interface{}{"first@logdna.com"}, "absence", 99)
+		ordered := orderChannelsLike(EMAIL, []interface{}{first, second}, []interface{}{second, changed})
+		assert.Equal([]interface{}{changed, second}, ordered)
+	})
+
+	t.Run("New remote channels are appended in their remote order", func(t *testing.T) {
+		ordered := orderChannelsLike(EMAIL, []interface{}{second}, []interface{}{third, first, second})
+		assert.Equal([]interface{}{second, third, first}, ordered)
+	})
+
+	t.Run("Channels deleted remotely are dropped", func(t *testing.T) {
+		ordered := orderChannelsLike(EMAIL, []interface{}{first, second, third}, []interface{}{third, first})
+		assert.Equal([]interface{}{first, third}, ordered)
+	})
+
+	t.Run("Nothing in state leaves the remote order untouched", func(t *testing.T) {
+		remote := []interface{}{second, first}
+		assert.Equal(remote, orderChannelsLike(EMAIL, []interface{}{}, remote))
+	})
+}
+
+func TestChannelMatching_suppressChannelReorder(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceView()
+	state := &terraform.InstanceState{
+		ID: "abc123",
+		Attributes: map[string]string{
+			"id":                              "abc123",
+			"name":                            "test",
+			"unknown_channels":                unknownChannelsKeep,
+			"manage_channels":                 manageChannelsAuthoritative,
+			"channel_secret_hashes.#":         "0",
+			"email_channel.#":                 "2",
+			"email_channel.0.emails.#":        "1",
+			"email_channel.0.emails.0":        "first@logdna.com",
+			"email_channel.0.immediate":       "false",
+			"email_channel.0.operator":        "absence",
+			"email_channel.0.terminal":        "true",
+			"email_channel.0.timezone":        "",
+			"email_channel.0.triggerinterval": "15m",
+			"email_channel.0.triggerlimit":    "15",
+			"email_channel.1.emails.#":        "1",
+			"email_channel.1.emails.0":        "second@logdna.com",
+			"email_channel.1.immediate":       "false",
+			"email_channel.1.operator":        "absence",
+			"email_channel.1.terminal":        "true",
+			"email_channel.1.timezone":        "",
+			"email_channel.1.triggerinterval": "15m",
+			"email_channel.1.triggerlimit":    "15",
+		},
+	}
+	emailConfig := func(email string) map[string]interface{} {
+		return map[string]interface{}{
+			"emails":          []interface{}{email},
+			"operator":        "absence",
+			"terminal":        "true",
+			"triggerinterval": "15m",
+			"triggerlimit":    15,
+		}
+	}
+	diffFor := func(config map[string]interface{}) *terraform.InstanceDiff {
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+		assert.Nil(err, "No errors")
+		return diff
+	}
+
+	t.Run("Swapping the channels is not a diff", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{
+			"name": "test",
+			"email_channel": []interface{}{
+				emailConfig("second@logdna.com"),
+				emailConfig("first@logdna.com"),
+			},
+		})
+		assert.True(diff == nil || diff.Empty(), "There is no diff")
+	})
+
+	t.Run("Removing every channel is a diff", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{"name": "test"})
+		assert.Equal("0", diff.Attributes["email_channel.#"].New, "email_channel is planned for removal")
+	})
+
+	t.Run("Changing a channel is a diff", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{
+			"name": "test",
+			"email_channel": []interface{}{
+				emailConfig("second@logdna.com"),
+				emailConfig("third@logdna.com"),
+			},
+		})
+		assert.False(diff == nil || diff.Empty(), "There is a diff")
+	})
+}
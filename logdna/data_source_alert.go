@@ -10,20 +10,30 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-var intSchema = &schema.Schema{
-	Type:     schema.TypeInt,
-	Computed: true,
-}
 var strSchema = &schema.Schema{
 	Type:     schema.TypeString,
 	Computed: true,
 }
-var alertProps = map[string]*schema.Schema{
-	"immediate":       strSchema,
-	"operator":        strSchema,
-	"terminal":        strSchema,
-	"triggerinterval": strSchema,
-	"triggerlimit":    intSchema,
+
+// computedSchema returns a copy of a resource schema where every attribute is
+// computed, so that data sources expose exactly what the resource maps
+func computedSchema(rs map[string]*schema.Schema) map[string]*schema.Schema {
+	computed := make(map[string]*schema.Schema, len(rs))
+	for key, s := range rs {
+		c := &schema.Schema{
+			Type:      s.Type,
+			Computed:  true,
+			Sensitive: s.Sensitive,
+		}
+		switch elem := s.Elem.(type) {
+		case *schema.Resource:
+			c.Elem = &schema.Resource{Schema: computedSchema(elem.Schema)}
+		case *schema.Schema:
+			c.Elem = &schema.Schema{Type: elem.Type}
+		}
+		computed[key] = c
+	}
+	return computed
 }
 
 func dataSourceAlertRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -32,6 +42,19 @@ func dataSourceAlertRead(ctx context.Context, d *schema.ResourceData, m interfac
 	pc := m.(*providerConfig)
 	id := d.Get("presetid").(string)
 
+	if id == "" {
+		found, err := findAlertByName(pc, d.Get("name").(string))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Cannot find the remote presetalert resource by name",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		id = found.PresetID
+	}
+
 	req := newRequestConfig(
 		pc,
 		"GET",
@@ -63,97 +86,76 @@ func dataSourceAlertRead(ctx context.Context, d *schema.ResourceData, m interfac
 	}
 	log.Printf("GET presetalert structure is as follows: %+v\n", alert)
 
-	appendError(d.Set("name", alert.Name), &diags)
-
-	ints, diags := alert.MapChannelsToSchema()
-	log.Printf("[DEBUG] presetalert MapChannelsToSchema result: %+v\n", ints)
-
-	for name, value := range ints {
-		if len(value) == 0 {
-			continue
-		}
-
-		key := fmt.Sprintf("%s_channel", name)
-		appendError(d.Set(key, value), &diags)
-	}
+	appendError(d.Set("presetid", id), &diags)
+	diags = append(diags, setAlertSchema(alert, d)...)
+	setMetadata(d, alert.resourceMetadata, webAppURL(pc, webPathAlert, id), &diags)
 
 	d.SetId(id)
 	return diags
 }
 
-func dataSourceAlert() *schema.Resource {
-	return &schema.Resource{
-		ReadContext: dataSourceAlertRead,
-		Schema: map[string]*schema.Schema{
-			"presetid": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"name": strSchema,
-			"email_channel": {
-				Type: schema.TypeList,
-				Elem: &schema.Resource{
-					Schema: getAlertSchema("email"),
-				},
-				Computed: true,
-			},
-			"pagerduty_channel": {
-				Type: schema.TypeList,
-				Elem: &schema.Resource{
-					Schema: getAlertSchema("pagerduty"),
-				},
-				Computed: true,
-			},
-			"slack_channel": {
-				Type: schema.TypeList,
-				Elem: &schema.Resource{
-					Schema: getAlertSchema("slack"),
-				},
-				Computed: true,
-			},
-			"webhook_channel": {
-				Type: schema.TypeList,
-				Elem: &schema.Resource{
-					Schema: getAlertSchema("webhook"),
-				},
-				Computed: true,
-			},
-		},
+// findAlertByName lists the preset alerts and returns the one with exactly `name`
+func findAlertByName(pc *providerConfig, name string) (*alertResponse, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		"/v1/config/presetalert",
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] GET presetalert list raw response body %s\n", body)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func getAlertSchema(chnl string) map[string]*schema.Schema {
-	schma := map[string]*schema.Schema{}
-	for key, value := range alertProps {
-		schma[key] = value
+	alerts := []alertResponse{}
+	if err = json.Unmarshal(body, &alerts); err != nil {
+		return nil, err
 	}
 
-	switch chnl {
-	case "email":
-		schma["timezone"] = strSchema
-		schma["emails"] = &schema.Schema{
-			Type: schema.TypeList,
-			Elem: &schema.Schema{
-				Type: schema.TypeString,
-			},
-			Computed: true,
+	var found []alertResponse
+	for _, alert := range alerts {
+		if alert.Name == name {
+			found = append(found, alert)
 		}
-	case "slack":
-		schma["url"] = strSchema
-	case "pagerduty":
-		schma["key"] = strSchema
-	case "webhook":
-		schma["bodytemplate"] = strSchema
-		schma["method"] = strSchema
-		schma["url"] = strSchema
-		schma["headers"] = &schema.Schema{
-			Type: schema.TypeMap,
-			Elem: &schema.Schema{
-				Type: schema.TypeString,
-			},
-			Computed: true,
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no preset alert is named %q", name)
+	case 1:
+		return &found[0], nil
+	default:
+		ids := make([]string, 0, len(found))
+		for _, alert := range found {
+			ids = append(ids, alert.PresetID)
 		}
+		return nil, fmt.Errorf("%d preset alerts are named %q, use one of their IDs instead: %v", len(found), name, ids)
 	}
+}
 
-	return schma
+func dataSourceAlert() *schema.Resource {
+	s := computedSchema(resourceAlert().Schema)
+	delete(s, "unknown_channels")
+	delete(s, "manage_channels")
+	delete(s, "channel_secret_hashes")
+
+	s["presetid"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"presetid", "name"},
+	}
+	s["name"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"presetid", "name"},
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceAlertRead,
+		Schema:      s,
+	}
 }
@@ -1,11 +1,16 @@
 package logdna
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 const ds = `
@@ -86,8 +91,8 @@ func TestDataAlert_BulkChannels(t *testing.T) {
 					testDataSourceAlertExists("data.logdna_alert.remote"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "name", "test"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.#", "2"),
-					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.%", "9"),
-					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.1.%", "9"),
+					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.%", "10"),
+					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.1.%", "10"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "email_channel.#", "0"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "pagerduty_channel.#", "0"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "slack_channel.#", "0"),
@@ -140,7 +145,7 @@ func TestDataSourceAlert_MultipleChannels(t *testing.T) {
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "slack_channel.0.triggerlimit", "15"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "slack_channel.0.url", "https://hooks.slack.com/services/identifier/secret"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.#", "1"),
-					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.%", "9"),
+					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.%", "10"),
 					// The JSON will have newlines per our API which uses JSON.stringify(obj, null, 2) as the value
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.bodytemplate", "{\n  \"fields\": {\n    \"description\": \"{{ matches }} matches found for {{ name }}\",\n    \"issuetype\": {\n      \"name\": \"Bug\"\n    },\n    \"project\": {\n      \"key\": \"test\"\n    },\n    \"summary\": \"Alert from {{ name }}\"\n  }\n}"),
 					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.headers.%", "2"),
@@ -159,6 +164,113 @@ func TestDataSourceAlert_MultipleChannels(t *testing.T) {
 	})
 }
 
+func testAlertServer(t *testing.T) *httptest.Server {
+	alert := `{
+		"presetid": "abc123",
+		"name": "Checkout errors",
+		"channels": [
+			{
+				"integration": "webhook",
+				"bodyFormat": "text",
+				"bodyTemplate": "{{ matches }} matches",
+				"headers": {"Authorization": "****cret"},
+				"method": "put",
+				"operator": "presence",
+				"triggerinterval": "15m",
+				"triggerlimit": 15,
+				"url": "https://yourwebhook/endpoint"
+			},
+			{
+				"integration": "opsgenie",
+				"key": "opsgenie-key",
+				"operator": "absence"
+			}
+		]
+	}`
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/config/presetalert":
+			fmt.Fprintf(w, `[%s, {"presetid": "def456", "name": "Duplicate"}, {"presetid": "ghi789", "name": "Duplicate"}]`, alert)
+		case "/v1/config/presetalert/abc123":
+			fmt.Fprint(w, alert)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": "not found"}`)
+		}
+	}))
+}
+
+func TestDataAlert_ReadByName(t *testing.T) {
+	assert := assert.New(t)
+	ts := testAlertServer(t)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	d := schema.TestResourceDataRaw(t, dataSourceAlert().Schema, map[string]interface{}{
+		"name": "Checkout errors",
+	})
+	diags := dataSourceAlertRead(context.Background(), d, pc)
+	assert.False(diags.HasError(), "No errors")
+	assert.Equal("abc123", d.Id())
+	assert.Equal("abc123", d.Get("presetid"))
+	assert.Equal("Checkout errors", d.Get("name"))
+
+	// Every field mapped by the resource is exposed
+	assert.Equal(1, d.Get("webhook_channel.#"))
+	assert.Equal("text", d.Get("webhook_channel.0.body_format"))
+	assert.Equal("{{ matches }} matches", d.Get("webhook_channel.0.bodytemplate"))
+	assert.Equal("****cret", d.Get("webhook_channel.0.headers.Authorization"))
+	assert.Equal("put", d.Get("webhook_channel.0.method"))
+	assert.Equal(1, d.Get("raw_channel.#"))
+	assert.Equal("opsgenie", d.Get("raw_channel.0.integration"))
+}
+
+func TestDataAlert_ReadByNameErrors(t *testing.T) {
+	ts := testAlertServer(t)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	cases := map[string]string{
+		"Missing":   `no preset alert is named "Missing"`,
+		"Duplicate": `2 preset alerts are named "Duplicate", use one of their IDs instead: [def456 ghi789]`,
+	}
+	for name, detail := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAlert().Schema, map[string]interface{}{
+				"name": name,
+			})
+			diags := dataSourceAlertRead(context.Background(), d, pc)
+			assert.True(t, diags.HasError(), "Expected an error")
+			assert.Equal(t, detail, diags[0].Detail)
+		})
+	}
+}
+
+func TestDataAlert_ByName(t *testing.T) {
+	wbArgs := map[string]map[string]string{"webhook": cloneDefaults(chnlDefaults["webhook"])}
+	cfg := fmtTestConfigResource("alert", "test", nilLst, alertDefaults, wbArgs, nilLst)
+	byName := `
+data "logdna_alert" "remote" {
+	name = logdna_alert.test.name
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf("%s\n%s", cfg, byName),
+				Check: resource.ComposeTestCheckFunc(
+					testDataSourceAlertExists("data.logdna_alert.remote"),
+					resource.TestCheckResourceAttrPair("data.logdna_alert.remote", "presetid", "logdna_alert.test", "id"),
+					resource.TestCheckResourceAttr("data.logdna_alert.remote", "name", "test"),
+					resource.TestCheckResourceAttr("data.logdna_alert.remote", "webhook_channel.0.body_format", "json"),
+				),
+			},
+		},
+	})
+}
+
 func testDataSourceAlertExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		_, ok := s.RootModule().Resources[name]
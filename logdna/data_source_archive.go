@@ -0,0 +1,69 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceArchiveSchema exposes the attributes of the archive resource,
+// without `verify` and without the credentials of each integration
+func dataSourceArchiveSchema() map[string]*schema.Schema {
+	s := computedSchema(resourceArchiveConfig().Schema)
+	delete(s, "verify")
+	for integration, fields := range archiveSecretFields {
+		config := s[fmt.Sprintf("%s_config", integration)].Elem.(*schema.Resource)
+		for _, field := range fields {
+			delete(config.Schema, field)
+		}
+	}
+	s["configured"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Computed: true,
+	}
+	return s
+}
+
+func dataSourceArchiveRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pc := m.(*providerConfig)
+	c, err := getArchiveConfig(pc)
+	if err != nil && !isNotFound(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot read the remote archive resource",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	// An account without archiving is not an error, every attribute is empty
+	if c == nil {
+		c = &archiveResponse{}
+	}
+	attrs, diags := flattenArchiveConfig(*c)
+	for integration, fields := range archiveSecretFields {
+		for _, block := range attrs[fmt.Sprintf("%s_config", integration)].([]interface{}) {
+			for _, field := range fields {
+				delete(block.(map[string]interface{}), field)
+			}
+		}
+	}
+	attrs["configured"] = c.Integration != ""
+
+	for key, value := range attrs {
+		appendError(d.Set(key, value), &diags)
+	}
+	d.SetId(archiveConfigID)
+	return diags
+}
+
+func dataSourceArchive() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceArchiveRead,
+		Schema:      dataSourceArchiveSchema(),
+	}
+}
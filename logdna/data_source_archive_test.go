@@ -0,0 +1,108 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func testArchiveResponseServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestDataArchive_Read(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := testArchiveResponseServer(http.StatusOK, `{
+		"integration": "dos",
+		"space": "logs",
+		"endpoint": "nyc3.digitaloceanspaces.com",
+		"accesskey": "****cess",
+		"secretkey": "****cret",
+		"status": "active"
+	}`)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	d := schema.TestResourceDataRaw(t, dataSourceArchive().Schema, map[string]interface{}{})
+	assert.False(dataSourceArchiveRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(archiveConfigID, d.Id())
+	assert.Equal(true, d.Get("configured"))
+	assert.Equal("dos", d.Get("integration"))
+	assert.Equal(archiveStatusActive, d.Get("status"))
+	assert.Equal([]interface{}{map[string]interface{}{
+		"space":    "logs",
+		"endpoint": "nyc3.digitaloceanspaces.com",
+	}}, d.Get("dos_config"), "Credentials are left out")
+	assert.Equal(0, d.Get("s3_config.#"))
+
+	for _, ts := range []*httptest.Server{
+		testArchiveResponseServer(http.StatusNotFound, `{"error": "Archiving is not configured"}`),
+		testArchiveResponseServer(http.StatusOK, `{}`),
+	} {
+		defer ts.Close()
+		pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+		d := schema.TestResourceDataRaw(t, dataSourceArchive().Schema, map[string]interface{}{})
+		assert.False(dataSourceArchiveRead(context.Background(), d, pc).HasError(), "Nothing configured is not an error")
+		assert.Equal(false, d.Get("configured"))
+		assert.Equal("", d.Get("integration"))
+	}
+
+	ts = testArchiveResponseServer(http.StatusInternalServerError, `{"error": "boom"}`)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	d = schema.TestResourceDataRaw(t, dataSourceArchive().Schema, map[string]interface{}{})
+	diags := dataSourceArchiveRead(context.Background(), d, pc)
+	assert.True(diags.HasError(), "Other errors are reported")
+	assert.Equal("Cannot read the remote archive resource", diags[0].Summary)
+}
+
+func TestDataArchive_schema(t *testing.T) {
+	assert := assert.New(t)
+	s := dataSourceArchive().Schema
+
+	assert.NotContains(s, "verify")
+	for integration, fields := range archiveSecretFields {
+		config := s[integration+"_config"].Elem.(*schema.Resource).Schema
+		for _, field := range fields {
+			assert.NotContains(config, field, integration)
+		}
+	}
+	assert.Contains(resourceArchiveConfig().Schema["ibm_config"].Elem.(*schema.Resource).Schema, "apikey", "The resource schema is not changed")
+}
+
+func TestDataArchive_Basic(t *testing.T) {
+	ts := testArchiveResponseServer(http.StatusOK, `{"integration": "gcs", "bucket": "logs", "projectid": "logdna", "status": "active"}`)
+	defer ts.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "logdna" {
+						servicekey = "%s"
+						url = "%s"
+					}
+
+					data "logdna_archive" "archive" {}
+				`, serviceKey, ts.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.logdna_archive.archive", "configured", "true"),
+					resource.TestCheckResourceAttr("data.logdna_archive.archive", "integration", "gcs"),
+					resource.TestCheckResourceAttr("data.logdna_archive.archive", "gcs_config.0.bucket", "logs"),
+					resource.TestCheckResourceAttr("data.logdna_archive.archive", "gcs_config.0.projectid", "logdna"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,265 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The kinds of exclusion rules that `rule_ids` can refer to
+var exclusionPreviewKinds = map[string]exclusionKind{
+	"ingestion": ingestionExclusions,
+	"stream":    streamExclusions,
+}
+
+// exclusionPreviewRule is a rule to evaluate, with the query already parsed
+type exclusionPreviewRule struct {
+	exclusionRule
+	query *queryNode
+}
+
+// exclusionRuleMatches reports whether the rule excludes the line: every one
+// of apps, hosts and query that is set must match it
+func exclusionRuleMatches(rule exclusionPreviewRule, line queryLine) bool {
+	if len(rule.Apps) > 0 && !matchesAnyValue(rule.Apps, line.app) {
+		return false
+	}
+	if len(rule.Hosts) > 0 && !matchesAnyValue(rule.Hosts, line.host) {
+		return false
+	}
+	return matchQuery(rule.query, line)
+}
+
+// matchesAnyValue compares an app or host with the values of a rule, which
+// may contain `*` wildcards
+func matchesAnyValue(values []string, actual string) bool {
+	for _, value := range values {
+		if wildcardPattern(value, true).MatchString(actual) {
+			return true
+		}
+	}
+	return false
+}
+
+func (k exclusionKind) getExclusionRule(pc *providerConfig, id string) (*exclusionRule, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		fmt.Sprintf("%s/%s", k.baseURL, id),
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	ex := exclusionRule{}
+	if err := json.Unmarshal(body, &ex); err != nil {
+		return nil, err
+	}
+	if ex.ID == "" {
+		ex.ID = id
+	}
+	return &ex, nil
+}
+
+// exclusionPreviewRules returns the inline rules and the existing ones, in
+// the order they are evaluated. Rules that are not active exclude nothing in
+// LogDNA, so they are left out.
+func exclusionPreviewRules(d *schema.ResourceData, pc *providerConfig) ([]exclusionPreviewRule, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	rules := make([]exclusionRule, 0)
+	for i, elem := range d.Get("rule").([]interface{}) {
+		rule := exclusionRuleFromDefinition(elem.(map[string]interface{}))
+		if len(rule.Apps) == 0 && len(rule.Hosts) == 0 && rule.Query == "" {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid exclusion rule",
+				Detail:   fmt.Sprintf("rule.%d must set at least one of apps, hosts or query", i),
+			})
+			continue
+		}
+		if !rule.Active {
+			log.Printf("[DEBUG] rule.%d is not active, it is not evaluated\n", i)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	kind := exclusionPreviewKinds[d.Get("exclusion_type").(string)]
+	for _, id := range listToStrings(d.Get("rule_ids").([]interface{})) {
+		rule, err := kind.getExclusionRule(pc, id)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Cannot read the remote %s resource", kind.name),
+				Detail:   fmt.Sprintf("%s: %s", id, err),
+			})
+			continue
+		}
+		if !rule.Active {
+			log.Printf("[DEBUG] %s %s is not active, it is not evaluated\n", kind.name, id)
+			continue
+		}
+		rules = append(rules, *rule)
+	}
+	sortExclusionRules(rules)
+
+	parsed := make([]exclusionPreviewRule, 0, len(rules))
+	for _, rule := range rules {
+		query, err := parseQuery(rule.Query)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid exclusion rule query",
+				Detail:   fmt.Sprintf("%q: invalid search query: %s", rule.Query, err),
+			})
+			continue
+		}
+		parsed = append(parsed, exclusionPreviewRule{exclusionRule: rule, query: query})
+	}
+	return parsed, diags
+}
+
+func dataSourceExclusionPreviewRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	rules, diags := exclusionPreviewRules(d, pc)
+	if diags.HasError() {
+		return diags
+	}
+
+	results := make([]interface{}, 0)
+	excluded := 0
+	indexOnly := 0
+	var summary []string
+	for _, elem := range d.Get("line").([]interface{}) {
+		l := elem.(map[string]interface{})
+		line := queryLine{
+			text:  l["line"].(string),
+			app:   l["app"].(string),
+			host:  l["host"].(string),
+			level: l["level"].(string),
+			meta:  make(map[string]string),
+		}
+		for k, v := range l["meta"].(map[string]interface{}) {
+			line.meta[k] = v.(string)
+		}
+
+		result := map[string]interface{}{
+			"excluded":   false,
+			"rule_id":    "",
+			"rule_title": "",
+			"indexonly":  false,
+		}
+		// The first matching rule applies to the line. An indexonly rule only
+		// keeps the line out of storage, so the line is not excluded.
+		for _, rule := range rules {
+			if exclusionRuleMatches(rule, line) {
+				result["excluded"] = !rule.IndexOnly
+				result["rule_id"] = rule.ID
+				result["rule_title"] = rule.Title
+				result["indexonly"] = rule.IndexOnly
+				if rule.IndexOnly {
+					indexOnly++
+				} else {
+					excluded++
+				}
+				break
+			}
+		}
+		results = append(results, result)
+		summary = append(summary, fmt.Sprintf("%v:%v:%s:%s", result["excluded"], result["indexonly"], result["rule_id"], result["rule_title"]))
+	}
+
+	appendError(d.Set("results", results), &diags)
+	appendError(d.Set("excluded_count", excluded), &diags)
+	appendError(d.Set("indexonly_count", indexOnly), &diags)
+	d.SetId(fmt.Sprintf("%d", schema.HashString(strings.Join(summary, "\n"))))
+	return diags
+}
+
+func dataSourceExclusionPreview() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceExclusionPreviewRead,
+		Schema: map[string]*schema.Schema{
+			"rule": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				AtLeastOneOf: []string{"rule", "rule_ids"},
+				Elem: &schema.Resource{
+					Schema: exclusionRuleElemSchema(),
+				},
+			},
+			"rule_ids": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				AtLeastOneOf: []string{"rule", "rule_ids"},
+				Elem:         &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNotEmpty},
+			},
+			"exclusion_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "ingestion",
+				ValidateFunc: validateOneOf([]string{"ingestion", "stream"}),
+			},
+			"line": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"line": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"app": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"host": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"level": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"meta": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"results": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"excluded":   {Type: schema.TypeBool, Computed: true},
+						"rule_id":    {Type: schema.TypeString, Computed: true},
+						"rule_title": {Type: schema.TypeString, Computed: true},
+						"indexonly":  {Type: schema.TypeBool, Computed: true},
+					},
+				},
+			},
+			"excluded_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"indexonly_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func testExclusionPreviewLines() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"line": "GET /health 200", "app": "nginx", "host": "web-1", "level": "info"},
+		map[string]interface{}{"line": "user logged in", "app": "auth", "host": "web-1", "level": "info", "meta": map[string]interface{}{"user": "abc"}},
+		map[string]interface{}{"line": "cache miss", "app": "api", "host": "bastion", "level": "debug"},
+		map[string]interface{}{"line": "payment failed", "app": "checkout", "host": "web-2", "level": "error"},
+	}
+}
+
+func TestDataExclusionPreview_Read(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newExclusionServer(streamExclusions,
+		exclusionRule{ID: "r-bastion", Title: "bastion", Active: true, Hosts: []string{"bastion"}, IndexOnly: true},
+		exclusionRule{ID: "r-paused", Title: "paused", Active: false, Apps: []string{"checkout"}},
+	)
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+
+	d := schema.TestResourceDataRaw(t, dataSourceExclusionPreview().Schema, map[string]interface{}{
+		"exclusion_type": "stream",
+		"rule_ids":       []interface{}{"r-bastion"},
+		"rule": []interface{}{
+			map[string]interface{}{"title": "health", "active": true, "apps": []interface{}{"nginx", "haproxy"}, "query": "/health"},
+			map[string]interface{}{"title": "debug", "active": true, "query": "level:(debug OR trace)", "priority": 1},
+			map[string]interface{}{"title": "auth users", "active": true, "apps": []interface{}{"auth"}, "query": "user:abc"},
+			map[string]interface{}{"title": "draft", "apps": []interface{}{"checkout"}},
+		},
+		"line": testExclusionPreviewLines(),
+	})
+	assert.False(dataSourceExclusionPreviewRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(3, d.Get("excluded_count"))
+	assert.Equal(0, d.Get("indexonly_count"))
+	assert.Equal([]interface{}{
+		map[string]interface{}{"excluded": true, "rule_id": "", "rule_title": "health", "indexonly": false},
+		map[string]interface{}{"excluded": true, "rule_id": "", "rule_title": "auth users", "indexonly": false},
+		map[string]interface{}{"excluded": true, "rule_id": "", "rule_title": "debug", "indexonly": false},
+		map[string]interface{}{"excluded": false, "rule_id": "", "rule_title": "", "indexonly": false},
+	}, d.Get("results"), "The debug rule is evaluated before the bastion one, the inactive draft rule is not evaluated")
+	assert.NotEmpty(d.Id())
+
+	// Existing rules that are not active are not evaluated, and indexonly
+	// rules do not exclude the lines they match
+	d = schema.TestResourceDataRaw(t, dataSourceExclusionPreview().Schema, map[string]interface{}{
+		"exclusion_type": "stream",
+		"rule_ids":       []interface{}{"r-bastion", "r-paused"},
+		"line":           testExclusionPreviewLines(),
+	})
+	assert.False(dataSourceExclusionPreviewRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(0, d.Get("excluded_count"))
+	assert.Equal(1, d.Get("indexonly_count"))
+	assert.Equal(false, d.Get("results.3.excluded"), "The paused rule excludes nothing")
+	assert.Equal(map[string]interface{}{
+		"excluded": false, "rule_id": "r-bastion", "rule_title": "bastion", "indexonly": true,
+	}, d.Get("results.2"))
+
+	// Unknown rules and rules that match every line are errors
+	d = schema.TestResourceDataRaw(t, dataSourceExclusionPreview().Schema, map[string]interface{}{
+		"rule_ids": []interface{}{"r-missing"},
+		"rule":     []interface{}{map[string]interface{}{"title": "everything", "active": true}},
+		"line":     testExclusionPreviewLines(),
+	})
+	diags := dataSourceExclusionPreviewRead(context.Background(), d, pc)
+	assert.Len(diags, 2)
+	assert.Equal("rule.0 must set at least one of apps, hosts or query", diags[0].Detail)
+	assert.Equal("Cannot read the remote ingestion exclusion resource", diags[1].Summary)
+}
+
+func TestDataExclusionPreview_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "logdna" {
+						servicekey = "%s"
+					}
+
+					data "logdna_exclusion_preview" "health" {
+						rule {
+							title  = "health"
+							active = true
+							apps   = ["nginx"]
+							query = "GET /health"
+						}
+
+						line {
+							line = "GET /health 200"
+							app  = "nginx"
+						}
+
+						line {
+							line = "GET /checkout 500"
+							app  = "nginx"
+						}
+					}
+				`, serviceKey),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.logdna_exclusion_preview.health", "excluded_count", "1"),
+					resource.TestCheckResourceAttr("data.logdna_exclusion_preview.health", "results.0.excluded", "true"),
+					resource.TestCheckResourceAttr("data.logdna_exclusion_preview.health", "results.0.rule_title", "health"),
+					resource.TestCheckResourceAttr("data.logdna_exclusion_preview.health", "results.1.excluded", "false"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,74 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func getStreamConfig(pc *providerConfig) (*streamConfig, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		"/v1/config/stream",
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	// The body holds the SASL password and TLS keys, it is never logged
+	log.Printf("[DEBUG] GET stream config, %d bytes received", len(body))
+	if err != nil {
+		return nil, err
+	}
+
+	c := streamConfig{}
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func dataSourceStreamConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pc := m.(*providerConfig)
+	c, err := getStreamConfig(pc)
+	if err != nil && !isNotFound(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot read the remote stream config resource",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	// Streaming is not set up on the account, which is not an error
+	if c == nil {
+		c = &streamConfig{}
+	}
+	for key, value := range flattenStreamConfig(*c) {
+		appendError(d.Set(key, value), &diags)
+	}
+	appendError(d.Set("configured", len(c.Brokers) > 0), &diags)
+
+	d.SetId(streamConfigID)
+	return diags
+}
+
+func dataSourceStreamConfig() *schema.Resource {
+	s := computedSchema(resourceStreamConfig().Schema)
+	delete(s, "password")
+	delete(s["tls"].Elem.(*schema.Resource).Schema, "client_key")
+	s["configured"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceStreamConfigRead,
+		Schema:      s,
+	}
+}
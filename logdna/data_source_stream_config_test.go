@@ -0,0 +1,76 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataStreamConfig_Read(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"brokers": ["broker-1.example.org:9090"], "topic": "logs", "user": "logdna", "status": "active", "saslmechanism": "scram-sha-512", "tls": true}`)
+	}))
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	d := schema.TestResourceDataRaw(t, dataSourceStreamConfig().Schema, map[string]interface{}{})
+	assert.False(dataSourceStreamConfigRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(streamConfigID, d.Id())
+	assert.Equal(true, d.Get("configured"))
+	assert.Equal([]interface{}{"broker-1.example.org:9090"}, d.Get("brokers"))
+	assert.Equal("logs", d.Get("topic"))
+	assert.Equal("logdna", d.Get("user"))
+	assert.Equal("active", d.Get("status"))
+	assert.Equal("scram-sha-512", d.Get("sasl_mechanism"))
+	assert.Equal(true, d.Get("tls.0.enabled"))
+	assert.NotContains(dataSourceStreamConfig().Schema, "password")
+	assert.NotContains(dataSourceStreamConfig().Schema["tls"].Elem.(*schema.Resource).Schema, "client_key")
+
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "Stream is not configured"}`)
+	}))
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	d = schema.TestResourceDataRaw(t, dataSourceStreamConfig().Schema, map[string]interface{}{})
+	assert.False(dataSourceStreamConfigRead(context.Background(), d, pc).HasError(), "Nothing configured is not an error")
+	assert.Equal(false, d.Get("configured"))
+	assert.Equal(0, d.Get("brokers.#"))
+	assert.Equal("", d.Get("topic"))
+}
+
+func TestDataStreamConfig_Basic(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"brokers": [], "topic": "", "user": ""}`)
+	}))
+	defer ts.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "logdna" {
+						servicekey = "%s"
+						url = "%s"
+					}
+
+					data "logdna_stream_config" "stream" {}
+				`, serviceKey, ts.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.logdna_stream_config.stream", "configured", "false"),
+					resource.TestCheckResourceAttr("data.logdna_stream_config.stream", "brokers.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,170 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceViewRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pc := m.(*providerConfig)
+	id := d.Get("viewid").(string)
+
+	if id == "" {
+		found, err := findViewByName(pc, d.Get("name").(string))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Cannot find the remote view resource by name",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		id = found.ViewID
+	}
+
+	req := newRequestConfig(
+		pc,
+		"GET",
+		fmt.Sprintf("/v1/config/view/%s", id),
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+
+	log.Printf("[DEBUG] GET view raw response body %s\n", body)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot read the remote view resource",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	view := viewResponse{}
+	err = json.Unmarshal(body, &view)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot unmarshal response from the remote view resource",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	log.Printf("[DEBUG] GET view structure is as follows: %+v\n", view)
+
+	categories, err := listCategories(pc, viewCategoryType)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot map the categories of the view to their IDs",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	flat, flatDiags := flattenView(view)
+	diags = append(diags, flatDiags...)
+	flat["viewid"] = id
+	flat["category_ids"] = matchCategoryIds(categories, view.Category)
+	for key, value := range flattenMetadata(view.resourceMetadata, webAppURL(pc, webPathView, id)) {
+		flat[key] = value
+	}
+
+	for key, value := range flat {
+		appendError(d.Set(key, value), &diags)
+	}
+
+	d.SetId(id)
+	return diags
+}
+
+func listViews(pc *providerConfig) ([]viewResponse, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		"/v1/config/view",
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] GET view list raw response body %s\n", body)
+	if err != nil {
+		return nil, err
+	}
+
+	views := []viewResponse{}
+	if err = json.Unmarshal(body, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// findViewByName lists the views and returns the one with exactly `name`
+func findViewByName(pc *providerConfig, name string) (*viewResponse, error) {
+	views, err := listViews(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []viewResponse
+	for _, view := range views {
+		if view.Name == name {
+			found = append(found, view)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no view is named %q", name)
+	case 1:
+		return &found[0], nil
+	default:
+		ids := make([]string, 0, len(found))
+		for _, view := range found {
+			ids = append(ids, view.ViewID)
+		}
+		return nil, fmt.Errorf("%d views are named %q, use one of their IDs instead: %v", len(found), name, ids)
+	}
+}
+
+// viewDataSourceSchema is the schema of a view as exposed by the data sources
+func viewDataSourceSchema() map[string]*schema.Schema {
+	s := computedSchema(resourceView().Schema)
+	delete(s, "unknown_channels")
+	delete(s, "manage_channels")
+	delete(s, "channel_secret_hashes")
+	s["viewid"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	return s
+}
+
+func dataSourceView() *schema.Resource {
+	s := viewDataSourceSchema()
+	s["viewid"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"viewid", "name"},
+	}
+	s["name"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"viewid", "name"},
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceViewRead,
+		Schema:      s,
+	}
+}
@@ -0,0 +1,143 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func testViewServer() *httptest.Server {
+	checkout := `{
+		"viewID": "abc123",
+		"name": "Checkout errors",
+		"query": "level:error",
+		"apps": ["checkout"],
+		"hosts": ["web-1"],
+		"category": ["Payments"],
+		"createdAt": 1600000000000,
+		"updatedAt": "2021-01-02T03:04:05Z",
+		"channels": [
+			{"integration": "email", "emails": ["oncall@logdna.com"], "operator": "presence", "triggerlimit": 15, "triggerinterval": "15m"}
+		]
+	}`
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/config/view":
+			fmt.Fprintf(w, `[%s,
+				{"viewID": "def456", "name": "Checkout latency", "apps": ["checkout"], "tags": ["perf"]},
+				{"viewID": "ghi789", "name": "Duplicate", "hosts": ["web-2"]},
+				{"viewID": "jkl012", "name": "Duplicate", "hosts": ["web-2"]}
+			]`, checkout)
+		case "/v1/config/view/abc123":
+			fmt.Fprint(w, checkout)
+		case "/v1/config/categories/views":
+			fmt.Fprint(w, `[{"id": "cat1", "name": "Payments", "type": "views"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": "not found"}`)
+		}
+	}))
+}
+
+func TestDataView_ReadByName(t *testing.T) {
+	assert := assert.New(t)
+	ts := testViewServer()
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL, account: "a1b2c3d4e5"}
+
+	d := schema.TestResourceDataRaw(t, dataSourceView().Schema, map[string]interface{}{
+		"name": "Checkout errors",
+	})
+	diags := dataSourceViewRead(context.Background(), d, pc)
+	assert.False(diags.HasError(), "No errors")
+	assert.Equal("abc123", d.Id())
+	assert.Equal("abc123", d.Get("viewid"))
+	assert.Equal("level:error", d.Get("query"))
+	assert.Equal([]interface{}{"Payments"}, d.Get("categories"))
+	assert.Equal([]interface{}{"views:cat1"}, d.Get("category_ids"))
+	assert.Equal(1, d.Get("email_channel.#"))
+	assert.Equal("oncall@logdna.com", d.Get("email_channel.0.emails.0"))
+	assert.Equal("2020-09-13T12:26:40Z", d.Get("created_at"))
+	assert.Equal("2021-01-02T03:04:05Z", d.Get("updated_at"))
+	assert.Equal("", d.Get("created_by"), "Not returned for views")
+	assert.Equal(ts.URL+"/a1b2c3d4e5/logs/view/abc123", d.Get("url"))
+
+	d = schema.TestResourceDataRaw(t, dataSourceView().Schema, map[string]interface{}{
+		"name": "Duplicate",
+	})
+	diags = dataSourceViewRead(context.Background(), d, pc)
+	assert.True(diags.HasError(), "Ambiguous names are an error")
+	assert.Equal(`2 views are named "Duplicate", use one of their IDs instead: [ghi789 jkl012]`, diags[0].Detail)
+}
+
+func TestDataViews_Read(t *testing.T) {
+	assert := assert.New(t)
+	ts := testViewServer()
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	cases := []struct {
+		filters  map[string]interface{}
+		expected []interface{}
+	}{
+		{map[string]interface{}{}, []interface{}{"abc123", "def456", "ghi789", "jkl012"}},
+		{map[string]interface{}{"name_regex": "^Checkout"}, []interface{}{"abc123", "def456"}},
+		{map[string]interface{}{"category": "payments"}, []interface{}{"abc123"}},
+		{map[string]interface{}{"app": "checkout", "tag": "perf"}, []interface{}{"def456"}},
+		{map[string]interface{}{"host": "web-2"}, []interface{}{"ghi789", "jkl012"}},
+		{map[string]interface{}{"host": "web-3"}, []interface{}{}},
+	}
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, dataSourceViews().Schema, c.filters)
+		diags := dataSourceViewsRead(context.Background(), d, pc)
+		assert.False(diags.HasError(), "No errors")
+		assert.Equal(c.expected, d.Get("ids"), c.filters)
+		assert.Equal(len(c.expected), d.Get("views.#"), c.filters)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceViews().Schema, map[string]interface{}{"category": "Payments"})
+	assert.False(dataSourceViewsRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal("Checkout errors", d.Get("views.0.name"))
+	assert.Equal("views:cat1", d.Get("views.0.category_ids.0"))
+	assert.Equal("presence", d.Get("views.0.email_channel.0.operator"))
+}
+
+func TestDataView_Basic(t *testing.T) {
+	chArgs := map[string]map[string]string{"email": cloneDefaults(chnlDefaults["email"])}
+	viewCfg := fmtTestConfigResource("view", "new", nilLst, viewDefaults, chArgs, nilLst)
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`%s
+data "logdna_view" "by_id" {
+	viewid = logdna_view.new.id
+}
+
+data "logdna_view" "by_name" {
+	name = logdna_view.new.name
+	depends_on = [logdna_view.new]
+}
+
+data "logdna_views" "filtered" {
+	name_regex = "^${logdna_view.new.name}$"
+}`, viewCfg),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.logdna_view.by_id", "name", "test"),
+					resource.TestCheckResourceAttr("data.logdna_view.by_id", "email_channel.#", "1"),
+					resource.TestCheckResourceAttr("data.logdna_view.by_id", "email_channel.0.%", "7"),
+					resource.TestCheckResourceAttrPair("data.logdna_view.by_name", "viewid", "logdna_view.new", "id"),
+					resource.TestCheckResourceAttr("data.logdna_views.filtered", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.logdna_views.filtered", "ids.0", "logdna_view.new", "id"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,133 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceViewsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pc := m.(*providerConfig)
+
+	// Validated by the schema
+	nameRegex := regexp.MustCompile(d.Get("name_regex").(string))
+	category := d.Get("category").(string)
+	app := d.Get("app").(string)
+	host := d.Get("host").(string)
+	tag := d.Get("tag").(string)
+
+	views, err := listViews(pc)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot list the remote view resources",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	categories, err := listCategories(pc, viewCategoryType)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot map the categories of the views to their IDs",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	ids := make([]string, 0)
+	matched := make([]interface{}, 0)
+	for _, view := range views {
+		if !nameRegex.MatchString(view.Name) ||
+			(category != "" && !containsString(view.Category, category, true)) ||
+			(app != "" && !containsString(view.Apps, app, false)) ||
+			(host != "" && !containsString(view.Hosts, host, false)) ||
+			(tag != "" && !containsString(view.Tags, tag, false)) {
+			continue
+		}
+
+		flat, flatDiags := flattenView(view)
+		diags = append(diags, flatDiags...)
+		flat["viewid"] = view.ViewID
+		flat["category_ids"] = matchCategoryIds(categories, view.Category)
+		for key, value := range flattenMetadata(view.resourceMetadata, webAppURL(pc, webPathView, view.ViewID)) {
+			flat[key] = value
+		}
+
+		ids = append(ids, view.ViewID)
+		matched = append(matched, flat)
+	}
+
+	appendError(d.Set("ids", ids), &diags)
+	appendError(d.Set("views", matched), &diags)
+
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+	d.SetId(fmt.Sprintf("%d", schema.HashString(strings.Join(sorted, ","))))
+	return diags
+}
+
+func containsString(list []string, value string, foldCase bool) bool {
+	for _, elem := range list {
+		if elem == value || (foldCase && strings.EqualFold(elem, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRegex(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := regexp.Compile(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q: invalid regular expression: %s", key, err))
+	}
+	return
+}
+
+func dataSourceViews() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceViewsRead,
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRegex,
+			},
+			"category": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"app": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tag": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"views": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: viewDataSourceSchema(),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceWebhookPreviewRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	vars := make(map[string]string, len(webhookVariables))
+	for name, value := range webhookVariables {
+		vars[name] = value
+	}
+	for name, value := range d.Get("variables").(map[string]interface{}) {
+		vars[name] = value.(string)
+	}
+
+	url := d.Get("url").(string)
+	body := d.Get("bodytemplate").(string)
+	headers := make(map[string]string)
+	for k, v := range d.Get("headers").(map[string]interface{}) {
+		headers[k] = renderWebhookTemplate(v.(string), vars)
+	}
+
+	renderedURL := renderWebhookTemplate(url, vars)
+	renderedBody := renderWebhookBody(d.Get("body_format").(string), body, vars)
+	log.Printf("[DEBUG] webhook preview rendered a body of %d bytes\n", len(renderedBody))
+
+	appendError(d.Set("rendered_url", renderedURL), &diags)
+	appendError(d.Set("rendered_method", strings.ToUpper(d.Get("method").(string))), &diags)
+	appendError(d.Set("rendered_headers", headers), &diags)
+	appendError(d.Set("rendered_body", renderedBody), &diags)
+
+	d.SetId(fmt.Sprintf("%d", schema.HashString(fmt.Sprintf("%s|%s", renderedURL, renderedBody))))
+	return diags
+}
+
+func dataSourceWebhookPreview() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceWebhookPreviewRead,
+		Schema: map[string]*schema.Schema{
+			"url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Sensitive:    true,
+				ValidateFunc: validateWebhookTemplate,
+			},
+			"method": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "post",
+			},
+			"headers": {
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validateWebhookTemplate,
+			},
+			"body_format": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      bodyFormatJSON,
+				ValidateFunc: validateWebhookBodyFormat,
+			},
+			"bodytemplate": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateWebhookTemplate,
+			},
+			"variables": {
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+			"rendered_url": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"rendered_method": strSchema,
+			"rendered_headers": {
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed:  true,
+				Sensitive: true,
+			},
+			"rendered_body": strSchema,
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataWebhookPreview_Read(t *testing.T) {
+	assert := assert.New(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourceWebhookPreview().Schema, map[string]interface{}{
+		"url":          "https://yourwebhook/endpoint/{{ account }}",
+		"headers":      map[string]interface{}{"X-View": "{{ name }}"},
+		"bodytemplate": `{"summary": "{{ matches }} matches found for {{ name }}"}`,
+		"variables":    map[string]interface{}{"matches": "42"},
+	})
+
+	diags := dataSourceWebhookPreviewRead(context.Background(), d, nil)
+	assert.False(diags.HasError(), "No errors")
+	assert.NotEmpty(d.Id(), "ID is set")
+	assert.Equal(fmt.Sprintf("https://yourwebhook/endpoint/%s", webhookVariables["account"]), d.Get("rendered_url"))
+	assert.Equal("POST", d.Get("rendered_method"))
+	assert.Equal(webhookVariables["name"], d.Get("rendered_headers.X-View"))
+	assert.Equal("{\n  \"summary\": \"42 matches found for My View\"\n}", d.Get("rendered_body"))
+}
+
+func TestDataWebhookPreview_ErrorsPlaceholder(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`%s
+data "logdna_webhook_preview" "preview" {
+	url          = "https://yourwebhook/endpoint"
+	bodytemplate = jsonencode({ summary = "{{ matchs }}" })
+}`, fmtProviderBlock()),
+				ExpectError: regexp.MustCompile(`unknown placeholder "{{ matchs }}"`),
+			},
+		},
+	})
+}
+
+func TestDataWebhookPreview_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`%s
+data "logdna_webhook_preview" "preview" {
+	url          = "https://yourwebhook/endpoint"
+	bodytemplate = jsonencode({ summary = "Alert from {{ name }}" })
+	variables    = { name = "Checkout" }
+}`, fmtProviderBlock()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.logdna_webhook_preview.preview", "rendered_url", "https://yourwebhook/endpoint"),
+					resource.TestCheckResourceAttr("data.logdna_webhook_preview.preview", "rendered_body", "{\n  \"summary\": \"Alert from Checkout\"\n}"),
+				),
+			},
+		},
+	})
+}
@@ -1,16 +1,81 @@
 package logdna
 
-import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// exclusionRule is a rule as returned by the API
 type exclusionRule struct {
-	ID     string   `json:"id,omitempty"`
-	Title  string   `json:"title"`
-	Active bool     `json:"active"`
-	Apps   []string `json:"apps"`
-	Hosts  []string `json:"hosts"`
-	Query  string   `json:"query"`
+	ID        string   `json:"id,omitempty"`
+	Title     string   `json:"title"`
+	Active    bool     `json:"active"`
+	IndexOnly bool     `json:"indexonly"`
+	Priority  int      `json:"priority,omitempty"`
+	Apps      []string `json:"apps"`
+	Hosts     []string `json:"hosts"`
+	Query     string   `json:"query"`
+	resourceMetadata
+}
+
+// exclusionRuleRequest is the body of a POST or PATCH of a rule. It holds no
+// metadata, and a rule without a priority sends a null one to clear it.
+type exclusionRuleRequest struct {
+	Title     string   `json:"title"`
+	Active    bool     `json:"active"`
+	IndexOnly bool     `json:"indexonly"`
+	Priority  *int     `json:"priority"`
+	Apps      []string `json:"apps"`
+	Hosts     []string `json:"hosts"`
+	Query     string   `json:"query"`
+}
+
+func (ex exclusionRule) request() exclusionRuleRequest {
+	req := exclusionRuleRequest{
+		Title:     ex.Title,
+		Active:    ex.Active,
+		IndexOnly: ex.IndexOnly,
+		Apps:      ex.Apps,
+		Hosts:     ex.Hosts,
+		Query:     ex.Query,
+	}
+	if ex.Priority != 0 {
+		priority := ex.Priority
+		req.Priority = &priority
+	}
+	return req
+}
+
+// exclusionKind is what differs between ingestion and stream exclusions: the
+// rules, their schema and how they are managed are the same
+type exclusionKind struct {
+	name    string
+	baseURL string
+	webPath string
+	// ID of the exclusion set resource, which owns every rule of the kind
+	setID string
 }
 
+var (
+	ingestionExclusions = exclusionKind{
+		name:    "ingestion exclusion",
+		baseURL: baseIngestionExclusionUrl,
+		webPath: webPathIngestionExclusion,
+		setID:   "ingestion",
+	}
+	streamExclusions = exclusionKind{
+		name:    "stream exclusion",
+		baseURL: baseStreamExclusionUrl,
+		webPath: webPathStreamExclusion,
+		setID:   "stream",
+	}
+)
+
 var exclusionRuleAtLeastOneOfFields = []string{"apps", "hosts", "query"}
 
 var exclusionRuleSchema = map[string]*schema.Schema{
@@ -28,6 +93,21 @@ var exclusionRuleSchema = map[string]*schema.Schema{
 		Default:  false,
 		Optional: true,
 	},
+	"indexonly": {
+		Type:     schema.TypeBool,
+		Default:  false,
+		Optional: true,
+	},
+	"priority": {
+		Type:     schema.TypeInt,
+		Optional: true,
+		ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+			if v := val.(int); v < 1 {
+				errs = append(errs, fmt.Errorf("%q must be at least 1, got: %d", key, v))
+			}
+			return
+		},
+	},
 	"apps": {
 		Type:         schema.TypeList,
 		Elem:         &schema.Schema{Type: schema.TypeString},
@@ -43,8 +123,180 @@ var exclusionRuleSchema = map[string]*schema.Schema{
 		AtLeastOneOf: exclusionRuleAtLeastOneOfFields,
 	},
 	"query": {
-		Type:         schema.TypeString,
-		Optional:     true,
-		AtLeastOneOf: exclusionRuleAtLeastOneOfFields,
+		Type:             schema.TypeString,
+		Optional:         true,
+		AtLeastOneOf:     exclusionRuleAtLeastOneOfFields,
+		ValidateFunc:     validateQuery,
+		DiffSuppressFunc: suppressQueryDiff,
 	},
 }
+
+// exclusionRuleElemSchema is the schema of a rule nested in a block, where the
+// id is unknown and AtLeastOneOf cannot refer to the fields of the rule
+func exclusionRuleElemSchema() map[string]*schema.Schema {
+	elem := make(map[string]*schema.Schema)
+	for _, k := range []string{"title", "active", "indexonly", "priority", "apps", "hosts", "query"} {
+		s := *exclusionRuleSchema[k]
+		s.AtLeastOneOf = nil
+		s.DiffSuppressFunc = nil
+		s.Computed = false
+		elem[k] = &s
+	}
+	return elem
+}
+
+// sortExclusionRules orders the rules the way they are evaluated: by
+// priority, lowest first, then rules without a priority. Ties are broken by
+// title and ID so that the order never depends on the API.
+func sortExclusionRules(rules []exclusionRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Priority != b.Priority {
+			if a.Priority == 0 || b.Priority == 0 {
+				return b.Priority == 0
+			}
+			return a.Priority < b.Priority
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.ID < b.ID
+	})
+}
+
+func generateExclusionRule(d *schema.ResourceData) exclusionRuleRequest {
+	return exclusionRule{
+		Title:     d.Get("title").(string),
+		Active:    d.Get("active").(bool),
+		IndexOnly: d.Get("indexonly").(bool),
+		Priority:  d.Get("priority").(int),
+		Apps:      listToStrings(d.Get("apps").([]interface{})),
+		Hosts:     listToStrings(d.Get("hosts").([]interface{})),
+		Query:     d.Get("query").(string),
+	}.request()
+}
+
+func (k exclusionKind) setExclusionRule(pc *providerConfig, ex exclusionRule, d *schema.ResourceData, diags *diag.Diagnostics) {
+	appendError(d.Set("title", ex.Title), diags)
+	appendError(d.Set("active", ex.Active), diags)
+	appendError(d.Set("indexonly", ex.IndexOnly), diags)
+	appendError(d.Set("priority", ex.Priority), diags)
+	appendError(d.Set("apps", ex.Apps), diags)
+	appendError(d.Set("hosts", ex.Hosts), diags)
+	appendError(d.Set("query", ex.Query), diags)
+	setMetadata(d, ex.resourceMetadata, webAppURL(pc, k.webPath, d.Id()), diags)
+}
+
+func (k exclusionKind) create(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pc := m.(*providerConfig)
+	req := newRequestConfig(
+		pc,
+		"POST",
+		k.baseURL,
+		generateExclusionRule(d),
+	)
+
+	body, err := req.MakeRequest()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	exn := exclusionRule{}
+	err = json.Unmarshal(body, &exn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(exn.ID)
+	k.setExclusionRule(pc, exn, d, &diags)
+
+	return diags
+}
+
+func (k exclusionKind) read(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pc := m.(*providerConfig)
+	req := newRequestConfig(
+		pc,
+		"GET",
+		fmt.Sprintf("%s/%s", k.baseURL, d.Id()),
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Cannot read the remote %s resource", k.name),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	ex := exclusionRule{}
+	err = json.Unmarshal(body, &ex)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Cannot unmarshal response from the remote %s resource", k.name),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	k.setExclusionRule(pc, ex, d, &diags)
+
+	return diags
+}
+
+func (k exclusionKind) update(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+	req := newRequestConfig(
+		pc,
+		"PATCH",
+		fmt.Sprintf("%s/%s", k.baseURL, d.Id()),
+		generateExclusionRule(d),
+	)
+
+	_, err := req.MakeRequest()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return k.read(ctx, d, m)
+}
+
+func (k exclusionKind) delete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+	req := newRequestConfig(
+		pc,
+		"DELETE",
+		fmt.Sprintf("%s/%s", k.baseURL, d.Id()),
+		nil,
+	)
+
+	_, err := req.MakeRequest()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func (k exclusionKind) resource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: k.create,
+		ReadContext:   k.read,
+		UpdateContext: k.update,
+		DeleteContext: k.delete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: withMetadata(exclusionRuleSchema),
+	}
+}
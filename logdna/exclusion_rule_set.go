@@ -0,0 +1,440 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// An exclusion set owns every exclusion rule of its kind. Rules are identified
+// by their title, and `rule_ids` maps each title to the ID of its rule. Rules
+// created outside of Terraform are read into `rule` so that they show up as
+// drift, and are deleted on apply, unless their title is in `unmanaged_titles`.
+
+func (k exclusionKind) listExclusionRules(pc *providerConfig) ([]exclusionRule, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		k.baseURL,
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] GET %s list raw response body %s\n", k.name, body)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []exclusionRule{}
+	if err := json.Unmarshal(body, &rules); err != nil {
+		return nil, err
+	}
+	sortExclusionRules(rules)
+	return rules, nil
+}
+
+func (k exclusionKind) createSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId(k.setID)
+
+	// An error would taint the set, and the next apply would delete every rule
+	// of it. The rules that failed are left out of `rule_ids` and planned again,
+	// so they are only warnings, unless no rule could be applied at all.
+	diags := k.applySet(ctx, d, m, diag.Warning)
+	if len(exclusionSetIds(d)) == 0 && len(diags) > 0 {
+		d.SetId("")
+		for i := range diags {
+			diags[i].Severity = diag.Error
+		}
+	}
+	return diags
+}
+
+func (k exclusionKind) updateSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return k.applySet(ctx, d, m, diag.Error)
+}
+
+// applySet creates, updates and deletes rules until the remote rules match
+// the configuration. A rule that is not in `rule_ids` yet adopts the remote
+// rule with the same title, if there is one, instead of creating a duplicate.
+// A remote rule is only ever matched to one title. Each failed change is
+// reported with the given severity.
+func (k exclusionKind) applySet(ctx context.Context, d *schema.ResourceData, m interface{}, severity diag.Severity) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pc := m.(*providerConfig)
+
+	remote, err := k.listExclusionRules(pc)
+	if err != nil {
+		// Nothing was applied, and the rules cannot be read back either. The
+		// state is kept as it was, rather than saving the planned rules.
+		d.Partial(true)
+		diags = append(diags, diag.Diagnostic{
+			Severity: severity,
+			Summary:  fmt.Sprintf("Cannot list the remote %s resources", k.name),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	byID := make(map[string]exclusionRule, len(remote))
+	for _, rule := range remote {
+		byID[rule.ID] = rule
+	}
+
+	ids := exclusionSetIds(d)
+	desired := exclusionSetByTitle(d.Get("rule").(*schema.Set).List())
+	unmanaged := exclusionSetUnmanagedTitles(d)
+	newIds := make(map[string]string, len(desired))
+	matched := make(map[string]bool, len(desired))
+	titles := sortedExclusionTitles(desired)
+
+	// Rules already in `rule_ids` are matched first, so that adopting a rule
+	// by its title never takes the rule of another title
+	existingRules := make(map[string]exclusionRule, len(desired))
+	for _, title := range titles {
+		if existing, found := byID[ids[title]]; found && !matched[existing.ID] {
+			existingRules[title] = existing
+			matched[existing.ID] = true
+		}
+	}
+	for _, title := range titles {
+		if _, found := existingRules[title]; found {
+			continue
+		}
+		for _, r := range remote {
+			if r.Title == title && !matched[r.ID] {
+				existingRules[title] = r
+				matched[r.ID] = true
+				break
+			}
+		}
+	}
+
+	for _, title := range titles {
+		rule := desired[title]
+		existing, found := existingRules[title]
+
+		var err error
+		id := existing.ID
+		switch {
+		case !found:
+			id, err = k.applyExclusionRule(pc, "POST", "", rule)
+		case !exclusionRulesEqual(rule, flattenExclusionRule(existing)):
+			_, err = k.applyExclusionRule(pc, "PATCH", existing.ID, rule)
+		}
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: severity,
+				Summary:  fmt.Sprintf("Cannot apply %s %q of the set", k.name, title),
+				Detail:   err.Error(),
+			})
+		}
+		if id != "" {
+			newIds[title] = id
+		}
+	}
+
+	for _, rule := range remote {
+		if matched[rule.ID] || unmanaged[rule.Title] {
+			continue
+		}
+		if _, err := k.applyExclusionRule(pc, "DELETE", rule.ID, nil); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: severity,
+				Summary:  fmt.Sprintf("Cannot delete %s %q, which is not in the set", k.name, rule.Title),
+				Detail:   err.Error(),
+			})
+		}
+	}
+
+	appendError(d.Set("rule_ids", newIds), &diags)
+	return append(diags, k.readSet(ctx, d, m)...)
+}
+
+// applyExclusionRule sends a single rule of the set and returns its ID
+func (k exclusionKind) applyExclusionRule(pc *providerConfig, method, id string, rule map[string]interface{}) (string, error) {
+	url := k.baseURL
+	if id != "" {
+		url = fmt.Sprintf("%s/%s", k.baseURL, id)
+	}
+	var payload interface{}
+	if rule != nil {
+		payload = exclusionRuleFromDefinition(rule).request()
+	}
+
+	req := newRequestConfig(pc, method, url, payload)
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] %s %s, payload is: %s", method, url, body)
+	if err != nil {
+		return "", err
+	}
+	if method != "POST" {
+		return id, nil
+	}
+
+	created := exclusionRule{}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (k exclusionKind) readSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pc := m.(*providerConfig)
+
+	remote, err := k.listExclusionRules(pc)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Cannot list the remote %s resources", k.name),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	byID := make(map[string]exclusionRule, len(remote))
+	for _, rule := range remote {
+		byID[rule.ID] = rule
+	}
+
+	current := exclusionSetByTitle(d.Get("rule").(*schema.Set).List())
+	unmanaged := exclusionSetUnmanagedTitles(d)
+	ids := exclusionSetIds(d)
+	managed := make(map[string]bool, len(ids))
+	rules := make([]interface{}, 0, len(remote))
+
+	for _, title := range sortedMapKeys(ids) {
+		id := ids[title]
+		rule, ok := byID[id]
+		if !ok {
+			log.Printf("[WARN] %s %q of the set (%s) was not found, removing it from state", k.name, title, id)
+			delete(ids, title)
+			continue
+		}
+		managed[id] = true
+
+		// The configured rule is kept when it is equivalent, e.g. a query that
+		// is only formatted differently
+		flat := flattenExclusionRule(rule)
+		if c, ok := current[title]; ok && exclusionRulesEqual(c, flat) {
+			flat = c
+		}
+		rules = append(rules, flat)
+	}
+	// Rules created outside of Terraform show up as drift
+	for _, rule := range remote {
+		if !managed[rule.ID] && !unmanaged[rule.Title] {
+			rules = append(rules, flattenExclusionRule(rule))
+		}
+	}
+
+	appendError(d.Set("rule", rules), &diags)
+	appendError(d.Set("rule_ids", ids), &diags)
+	return diags
+}
+
+// deleteSet deletes the rules managed by the set. Rules created outside of
+// Terraform are left alone.
+func (k exclusionKind) deleteSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pc := m.(*providerConfig)
+
+	ids := exclusionSetIds(d)
+	for _, title := range sortedMapKeys(ids) {
+		_, err := k.applyExclusionRule(pc, "DELETE", ids[title], nil)
+		if err != nil && !isNotFound(err) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Cannot delete %s %q of the set", k.name, title),
+				Detail:   err.Error(),
+			})
+			continue
+		}
+		delete(ids, title)
+	}
+
+	if diags.HasError() {
+		// Keep the rules that could not be deleted
+		appendError(d.Set("rule_ids", ids), &diags)
+		return diags
+	}
+	d.SetId("")
+	return nil
+}
+
+func flattenExclusionRule(ex exclusionRule) map[string]interface{} {
+	return map[string]interface{}{
+		"title":     ex.Title,
+		"active":    ex.Active,
+		"indexonly": ex.IndexOnly,
+		"priority":  ex.Priority,
+		"apps":      stringsToList(ex.Apps),
+		"hosts":     stringsToList(ex.Hosts),
+		"query":     ex.Query,
+	}
+}
+
+func exclusionRuleFromDefinition(rule map[string]interface{}) exclusionRule {
+	return exclusionRule{
+		Title:     rule["title"].(string),
+		Active:    rule["active"].(bool),
+		IndexOnly: rule["indexonly"].(bool),
+		Priority:  rule["priority"].(int),
+		Apps:      listToStrings(rule["apps"].([]interface{})),
+		Hosts:     listToStrings(rule["hosts"].([]interface{})),
+		Query:     rule["query"].(string),
+	}
+}
+
+// exclusionRulesEqual compares a configured rule with a remote one. Apps and
+// hosts are compared regardless of their order, queries regardless of their
+// formatting.
+func exclusionRulesEqual(rule, remote map[string]interface{}) bool {
+	for _, k := range []string{"title", "active", "indexonly"} {
+		if rule[k] != remote[k] {
+			return false
+		}
+	}
+	if rule["priority"].(int) != remote["priority"].(int) {
+		return false
+	}
+
+	queryA, errA := normalizeQuery(rule["query"].(string))
+	queryB, errB := normalizeQuery(remote["query"].(string))
+	if errA != nil || errB != nil {
+		queryA, queryB = rule["query"].(string), remote["query"].(string)
+	}
+	if queryA != queryB {
+		return false
+	}
+
+	for _, k := range []string{"apps", "hosts"} {
+		listA := strings.Join(sortedStrings(rule[k].([]interface{}), false), "\n")
+		listB := strings.Join(sortedStrings(remote[k].([]interface{}), false), "\n")
+		if listA != listB {
+			return false
+		}
+	}
+	return true
+}
+
+func exclusionSetByTitle(list []interface{}) map[string]map[string]interface{} {
+	rules := make(map[string]map[string]interface{}, len(list))
+	for _, elem := range list {
+		rule := elem.(map[string]interface{})
+		rules[rule["title"].(string)] = rule
+	}
+	return rules
+}
+
+func exclusionSetIds(d *schema.ResourceData) map[string]string {
+	ids := make(map[string]string)
+	for title, id := range d.Get("rule_ids").(map[string]interface{}) {
+		ids[title] = id.(string)
+	}
+	return ids
+}
+
+func exclusionSetUnmanagedTitles(d *schema.ResourceData) map[string]bool {
+	titles := make(map[string]bool)
+	for _, title := range d.Get("unmanaged_titles").(*schema.Set).List() {
+		titles[title.(string)] = true
+	}
+	return titles
+}
+
+func sortedExclusionTitles(rules map[string]map[string]interface{}) []string {
+	titles := make([]string, 0, len(rules))
+	for title := range rules {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+func sortedMapKeys(ids map[string]string) []string {
+	keys := make([]string, 0, len(ids))
+	for key := range ids {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// customizeExclusionSet rejects rules that share a title, rules that match
+// nothing, and rules whose title is unmanaged
+func customizeExclusionSet(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	unmanaged := make(map[string]bool)
+	for _, title := range d.Get("unmanaged_titles").(*schema.Set).List() {
+		unmanaged[title.(string)] = true
+	}
+
+	seen := make(map[string]bool)
+	for _, elem := range d.Get("rule").(*schema.Set).List() {
+		rule := elem.(map[string]interface{})
+		title := rule["title"].(string)
+		if title == "" {
+			continue
+		}
+		if seen[title] {
+			return fmt.Errorf("more than one rule of the set has the title %q", title)
+		}
+		seen[title] = true
+		if unmanaged[title] {
+			return fmt.Errorf("rule %q cannot be in the set, its title is in unmanaged_titles", title)
+		}
+		if len(rule["apps"].([]interface{})) == 0 && len(rule["hosts"].([]interface{})) == 0 && rule["query"].(string) == "" {
+			return fmt.Errorf("rule %q must set at least one of apps, hosts or query", title)
+		}
+	}
+	return nil
+}
+
+// exclusionSetElemSchema is the schema of a rule in the set, with a required
+// title
+func exclusionSetElemSchema() map[string]*schema.Schema {
+	elem := exclusionRuleElemSchema()
+	elem["title"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		ValidateFunc: validateNotEmpty,
+	}
+	return elem
+}
+
+func (k exclusionKind) setResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: k.createSet,
+		ReadContext:   k.readSet,
+		UpdateContext: k.updateSet,
+		DeleteContext: k.deleteSet,
+		CustomizeDiff: customizeExclusionSet,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"rule": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: exclusionSetElemSchema(),
+				},
+			},
+			"unmanaged_titles": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"rule_ids": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
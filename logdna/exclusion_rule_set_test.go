@@ -0,0 +1,260 @@
+package logdna
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func testExclusionSetRemoteRules() []exclusionRule {
+	return []exclusionRule{
+		{ID: "r-debug", Title: "debug", Active: true, Query: "level:debug", Priority: 1},
+		{ID: "r-manual", Title: "added by hand", Active: true, Apps: []string{"audit"}},
+		{ID: "r-keep", Title: "keep me", Hosts: []string{"bastion"}},
+	}
+}
+
+func TestExclusionSet_resources(t *testing.T) {
+	for name, k := range map[string]exclusionKind{
+		"logdna_ingestion_exclusion_set": ingestionExclusions,
+		"logdna_stream_exclusion_set":    streamExclusions,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			s := newExclusionServer(k, testExclusionSetRemoteRules()...)
+			defer s.Close()
+			pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+			r := Provider().ResourcesMap[name]
+
+			debug := map[string]interface{}{
+				"title":    "debug",
+				"active":   true,
+				"priority": 1,
+				"query":    "level:debug",
+			}
+			health := map[string]interface{}{
+				"title":     "health checks",
+				"active":    true,
+				"indexonly": true,
+				"apps":      []interface{}{"nginx"},
+				"query":     "GET /health",
+			}
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"rule":             []interface{}{debug, health},
+				"unmanaged_titles": []interface{}{"keep me"},
+			})
+			assert.False(r.CreateContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Equal(k.setID, d.Id())
+			assert.Equal([]string{
+				"POST " + k.baseURL,
+				"DELETE " + k.baseURL + "/r-manual",
+			}, s.requests, "The same rule is adopted, the unmanaged one is kept")
+			assert.Equal(map[string]interface{}{"debug": "r-debug", "health checks": "rule1"}, d.Get("rule_ids"))
+			assert.Equal(2, d.Get("rule.#"))
+			assert.Contains(s.rules, "r-keep")
+			assert.Equal(true, s.rules["rule1"].IndexOnly)
+
+			// Rules added outside of Terraform are drift
+			s.rules["r-new"] = exclusionRule{ID: "r-new", Title: "new", Query: "foo"}
+			s.rules["r-keep2"] = exclusionRule{ID: "r-keep2", Title: "keep me", Query: "bar"}
+			assert.False(r.ReadContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Equal(3, d.Get("rule.#"))
+			titles := []string{}
+			for _, rule := range d.Get("rule").(*schema.Set).List() {
+				titles = append(titles, rule.(map[string]interface{})["title"].(string))
+			}
+			assert.ElementsMatch([]string{"debug", "health checks", "new"}, titles)
+
+			// The configuration wins: the new rule is deleted, the changed one updated
+			s.requests = nil
+			debug["query"] = "level:(debug OR trace)"
+			d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"rule":             []interface{}{debug, health},
+				"unmanaged_titles": []interface{}{"keep me"},
+			})
+			d.SetId(k.setID)
+			assert.Nil(d.Set("rule_ids", map[string]interface{}{"debug": "r-debug", "health checks": "rule1"}))
+			assert.False(r.UpdateContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Equal([]string{
+				"PATCH " + k.baseURL + "/r-debug",
+				"DELETE " + k.baseURL + "/r-new",
+			}, s.requests)
+			assert.Equal("level:(debug OR trace)", s.rules["r-debug"].Query)
+			assert.Equal(2, d.Get("rule.#"))
+
+			// Only the rules of the set are deleted
+			s.requests = nil
+			assert.False(r.DeleteContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.ElementsMatch([]string{
+				"DELETE " + k.baseURL + "/r-debug",
+				"DELETE " + k.baseURL + "/rule1",
+			}, s.requests)
+			assert.Len(s.rules, 2)
+			assert.Equal("", d.Id())
+		})
+	}
+}
+
+func TestExclusionSet_CreateFails(t *testing.T) {
+	assert := assert.New(t)
+	k := ingestionExclusions
+	s := newExclusionServer(k)
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+	r := Provider().ResourcesMap["logdna_ingestion_exclusion_set"]
+
+	cfg := map[string]interface{}{"rule": []interface{}{
+		map[string]interface{}{"title": "debug", "query": "level:debug"},
+		map[string]interface{}{"title": "broken", "apps": []interface{}{"api"}},
+	}}
+
+	// A partial create is only a warning, so the set is not tainted
+	s.failTitle = "broken"
+	d := schema.TestResourceDataRaw(t, r.Schema, cfg)
+	diags := r.CreateContext(context.Background(), d, pc)
+	assert.False(diags.HasError(), "No errors")
+	assert.Len(diags, 1)
+	assert.Equal(`Cannot apply ingestion exclusion "broken" of the set`, diags[0].Summary)
+	assert.Equal(k.setID, d.Id())
+	assert.Equal(map[string]interface{}{"debug": "rule1"}, d.Get("rule_ids"))
+
+	// The next plan only adds the failed rule, which the next apply creates
+	state := d.State()
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(cfg), pc)
+	assert.Nil(err)
+	assert.NotNil(diff)
+	assert.False(diff.RequiresNew(), "The set is not replaced")
+	d, err = schema.InternalMap(r.Schema).Data(state, diff)
+	assert.Nil(err)
+	s.failTitle = ""
+	s.requests = nil
+	assert.False(r.UpdateContext(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal([]string{"POST " + k.baseURL}, s.requests, "The created rule is kept")
+	assert.Equal(map[string]interface{}{"debug": "rule1", "broken": "rule2"}, d.Get("rule_ids"))
+
+	// Nothing applied is an error, and no set is created
+	s = newExclusionServer(k)
+	defer s.Close()
+	s.failTitle = "broken"
+	pc = &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"rule": []interface{}{
+		map[string]interface{}{"title": "broken", "apps": []interface{}{"api"}},
+	}})
+	diags = r.CreateContext(context.Background(), d, pc)
+	assert.True(diags.HasError(), "The create fails")
+	assert.Equal("", d.Id())
+}
+
+func TestExclusionSet_UpdateListFails(t *testing.T) {
+	assert := assert.New(t)
+	k := ingestionExclusions
+	s := newExclusionServer(k)
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+	r := Provider().ResourcesMap["logdna_ingestion_exclusion_set"]
+
+	rule := map[string]interface{}{"title": "debug", "active": true, "query": "level:debug"}
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"rule": []interface{}{rule}})
+	assert.False(r.CreateContext(context.Background(), d, pc).HasError(), "No errors")
+	state := d.State()
+
+	changed := map[string]interface{}{"title": "debug", "active": true, "query": "level:(debug OR trace)"}
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"rule": []interface{}{changed},
+	}), pc)
+	assert.Nil(err)
+	assert.NotNil(diff)
+	d, err = schema.InternalMap(r.Schema).Data(state, diff)
+	assert.Nil(err)
+
+	// The API cannot be reached anymore
+	s.Close()
+	diags := r.UpdateContext(context.Background(), d, pc)
+	assert.True(diags.HasError(), "The update fails")
+	assert.Equal("Cannot list the remote ingestion exclusion resources", diags[0].Summary)
+	assert.Equal(state.Attributes, d.State().Attributes, "The state is kept as it was, so that the change is planned again")
+}
+
+func TestExclusionSet_matchedOnce(t *testing.T) {
+	assert := assert.New(t)
+	k := streamExclusions
+	// The rule of "b" was renamed to "a" outside of Terraform
+	s := newExclusionServer(k, exclusionRule{ID: "r-b", Title: "a", Query: "a"})
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+	r := Provider().ResourcesMap["logdna_stream_exclusion_set"]
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"rule": []interface{}{
+		map[string]interface{}{"title": "a", "query": "a"},
+		map[string]interface{}{"title": "b", "query": "b"},
+	}})
+	d.SetId(k.setID)
+	assert.Nil(d.Set("rule_ids", map[string]interface{}{"b": "r-b"}))
+	assert.False(r.UpdateContext(context.Background(), d, pc).HasError(), "No errors")
+	assert.ElementsMatch([]string{
+		"POST " + k.baseURL,
+		"PATCH " + k.baseURL + "/r-b",
+	}, s.requests, "The rule in rule_ids is kept, the other title gets a new one")
+	assert.Equal(map[string]interface{}{"a": "rule1", "b": "r-b"}, d.Get("rule_ids"))
+	assert.Len(s.rules, 2)
+}
+
+func TestExclusionSet_exclusionRulesEqual(t *testing.T) {
+	assert := assert.New(t)
+
+	remote := flattenExclusionRule(exclusionRule{
+		Title:    "debug",
+		Priority: 3,
+		Apps:     []string{"api", "web"},
+		Query:    "level:debug   app:api",
+	})
+	rule := func(changes map[string]interface{}) map[string]interface{} {
+		r := map[string]interface{}{
+			"title":     "debug",
+			"active":    false,
+			"indexonly": false,
+			"priority":  0,
+			"apps":      []interface{}{"web", "api"},
+			"hosts":     []interface{}{},
+			"query":     "level:debug app:api",
+		}
+		for k, v := range changes {
+			r[k] = v
+		}
+		return r
+	}
+
+	assert.True(exclusionRulesEqual(rule(map[string]interface{}{"priority": 3}), remote), "Order and formatting are not changes")
+	assert.False(exclusionRulesEqual(rule(nil), remote), "An unset priority clears the remote one")
+	assert.False(exclusionRulesEqual(rule(map[string]interface{}{"priority": 1}), remote))
+	assert.False(exclusionRulesEqual(rule(map[string]interface{}{"indexonly": true}), remote))
+	assert.False(exclusionRulesEqual(rule(map[string]interface{}{"hosts": []interface{}{"web-1"}}), remote))
+	assert.False(exclusionRulesEqual(rule(map[string]interface{}{"query": "level:info"}), remote))
+}
+
+func TestExclusionSet_customizeExclusionSet(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceIngestionExclusionSet()
+
+	diff := func(rules ...map[string]interface{}) error {
+		list := make([]interface{}, len(rules))
+		for i, rule := range rules {
+			list[i] = rule
+		}
+		_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"rule":             list,
+			"unmanaged_titles": []interface{}{"keep me"},
+		}), nil)
+		return err
+	}
+
+	assert.Nil(diff(map[string]interface{}{"title": "a", "query": "foo"}, map[string]interface{}{"title": "b", "apps": []interface{}{"api"}}))
+	assert.EqualError(
+		diff(map[string]interface{}{"title": "a", "query": "foo"}, map[string]interface{}{"title": "a", "query": "bar"}),
+		`more than one rule of the set has the title "a"`,
+	)
+	assert.EqualError(diff(map[string]interface{}{"title": "keep me", "query": "foo"}), `rule "keep me" cannot be in the set, its title is in unmanaged_titles`)
+	assert.EqualError(diff(map[string]interface{}{"title": "a"}), `rule "a" must set at least one of apps, hosts or query`)
+}
@@ -0,0 +1,178 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// exclusionServer is an in-memory API of the exclusion rules of one kind
+type exclusionServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	rules    map[string]exclusionRule
+	nextID   int
+	bodies   []map[string]interface{}
+	requests []string
+	// Writes of rules with this title fail
+	failTitle string
+}
+
+func newExclusionServer(k exclusionKind, rules ...exclusionRule) *exclusionServer {
+	s := &exclusionServer{rules: make(map[string]exclusionRule)}
+	for _, rule := range rules {
+		s.rules[rule.ID] = rule
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if r.Method != "GET" {
+			s.requests = append(s.requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+		}
+		id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, k.baseURL), "/")
+		rule, found := s.rules[id]
+		if !strings.HasPrefix(r.URL.Path, k.baseURL) || (id != "" && !found) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": "not found"}`)
+			return
+		}
+
+		if r.Method == "POST" || r.Method == "PATCH" {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			s.bodies = append(s.bodies, body)
+			if s.failTitle != "" && body["title"] == s.failTitle {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"error": "internal error"}`)
+				return
+			}
+
+			raw, _ := json.Marshal(body)
+			rule = exclusionRule{}
+			_ = json.Unmarshal(raw, &rule)
+			if r.Method == "POST" {
+				s.nextID++
+				id = fmt.Sprintf("rule%d", s.nextID)
+			}
+			rule.ID = id
+			s.rules[id] = rule
+		}
+
+		switch {
+		case r.Method == "DELETE":
+			delete(s.rules, id)
+			fmt.Fprint(w, `{}`)
+		case id == "" && r.Method == "GET":
+			list := make([]exclusionRule, 0, len(s.rules))
+			for _, rule := range s.rules {
+				list = append(list, rule)
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		default:
+			_ = json.NewEncoder(w).Encode(rule)
+		}
+	}))
+	return s
+}
+
+func TestExclusionRule_resources(t *testing.T) {
+	for name, k := range map[string]exclusionKind{
+		"logdna_ingestion_exclusion": ingestionExclusions,
+		"logdna_stream_exclusion":    streamExclusions,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			s := newExclusionServer(k)
+			defer s.Close()
+			pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL, account: "a1b2c3d4e5"}
+			r := Provider().ResourcesMap[name]
+
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"title":     "debug",
+				"active":    true,
+				"indexonly": true,
+				"priority":  2,
+				"query":     "level:debug",
+			})
+			assert.False(r.CreateContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Equal("rule1", d.Id())
+			assert.Equal(true, s.bodies[0]["indexonly"], "indexonly is sent")
+			assert.Equal(float64(2), s.bodies[0]["priority"], "priority is sent")
+			assert.Equal(true, d.Get("indexonly"))
+			assert.Equal(2, d.Get("priority"))
+			assert.Equal("/a1b2c3d4e5"+fmt.Sprintf(k.webPath, "rule1"), strings.TrimPrefix(d.Get("url").(string), s.URL))
+
+			assert.Nil(d.Set("indexonly", false))
+			assert.False(r.UpdateContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Equal(false, s.bodies[1]["indexonly"])
+			assert.NotContains(s.bodies[1], "id", "Only the settings of the rule are sent")
+			assert.NotContains(s.bodies[1], "createdAt")
+			assert.Equal(false, s.rules["rule1"].IndexOnly)
+			assert.Equal(false, d.Get("indexonly"))
+
+			assert.False(r.DeleteContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Empty(s.rules)
+			d.SetId("rule1")
+			diags := r.ReadContext(context.Background(), d, pc)
+			assert.True(diags.HasError(), "The rule is gone")
+			assert.Equal(fmt.Sprintf("Cannot read the remote %s resource", k.name), diags[0].Summary)
+
+			// Removing the priority is planned, and clears it with a null one
+			d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"apps": []interface{}{"api"}, "priority": 3})
+			assert.False(r.CreateContext(context.Background(), d, pc).HasError(), "No errors")
+			state := d.State()
+			diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{"apps": []interface{}{"api"}}), pc)
+			assert.Nil(err)
+			assert.NotNil(diff)
+			assert.Equal("0", diff.Attributes["priority"].New)
+			d, err = schema.InternalMap(r.Schema).Data(state, diff)
+			assert.Nil(err)
+			assert.False(r.UpdateContext(context.Background(), d, pc).HasError(), "No errors")
+			assert.Contains(s.bodies[3], "priority")
+			assert.Nil(s.bodies[3]["priority"])
+			assert.Equal(0, d.Get("priority"))
+		})
+	}
+}
+
+func TestExclusionRule_schema(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(resourceIngestionExclusion().Schema, resourceStreamExclusion().Schema, "Both resources share their schema")
+
+	_, errs := exclusionRuleSchema["priority"].ValidateFunc(0, "priority")
+	assert.Len(errs, 1)
+	assert.EqualError(errs[0], `"priority" must be at least 1, got: 0`)
+	_, errs = exclusionRuleSchema["priority"].ValidateFunc(1, "priority")
+	assert.Empty(errs)
+}
+
+func TestExclusionRule_sortExclusionRules(t *testing.T) {
+	assert := assert.New(t)
+
+	rules := []exclusionRule{
+		{ID: "e", Title: "b"},
+		{ID: "d", Title: "a"},
+		{ID: "c", Title: "z", Priority: 2},
+		{ID: "b", Title: "a", Priority: 1},
+		{ID: "a", Title: "a", Priority: 1},
+		{ID: "f", Title: "a"},
+	}
+	sortExclusionRules(rules)
+
+	ids := make([]string, len(rules))
+	for i, rule := range rules {
+		ids[i] = rule.ID
+	}
+	assert.Equal([]string{"a", "b", "c", "d", "f", "e"}, ids)
+}
@@ -0,0 +1,136 @@
+package logdna
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceMetadata holds the creation details returned along with configuration
+// objects. Not every endpoint returns every field, and missing fields are left
+// empty in state.
+type resourceMetadata struct {
+	CreatedAt *apiTimestamp `json:"createdAt,omitempty"`
+	UpdatedAt *apiTimestamp `json:"updatedAt,omitempty"`
+	CreatedBy string        `json:"createdBy,omitempty"`
+}
+
+// apiTimestamp decodes the timestamps of the API, which are either RFC 3339
+// strings or Unix epochs in seconds or milliseconds
+type apiTimestamp struct {
+	time.Time
+}
+
+// Epochs above this value are in milliseconds, i.e. after 2001-09-09 in ms
+const epochMillisThreshold = 1e12
+
+func (t *apiTimestamp) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch v := raw.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case float64:
+		t.Time = epochToTime(v)
+	case string:
+		if epoch, err := strconv.ParseFloat(v, 64); err == nil {
+			t.Time = epochToTime(epoch)
+			return nil
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return fmt.Errorf("cannot parse timestamp %q: %s", v, err)
+		}
+		t.Time = parsed
+	default:
+		return fmt.Errorf("unexpected timestamp %s", data)
+	}
+	return nil
+}
+
+func epochToTime(epoch float64) time.Time {
+	if epoch > epochMillisThreshold {
+		return time.Unix(0, int64(epoch)*int64(time.Millisecond))
+	}
+	return time.Unix(int64(epoch), 0)
+}
+
+// formatTimestamp returns the timestamp in RFC 3339, in UTC, or an empty string
+// when the API did not return it
+func formatTimestamp(t *apiTimestamp) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Paths of the web app pages of each kind of resource
+const (
+	webPathView               = "/logs/view/%s"
+	webPathAlert              = "/manage/alerts/%s"
+	webPathCategory           = "/manage/categories/%s/%s"
+	webPathIngestionExclusion = "/manage/usage/exclusion-rules/%s"
+	webPathStreamExclusion    = "/manage/streaming/exclusion-rules/%s"
+)
+
+// webAppURL returns a link to `path` in the web app matching the API URL of
+// the provider, e.g. https://app.logdna.com/<account> for
+// https://api.logdna.com. Pages of the web app are scoped to an account, so
+// no link is returned when the provider has no account configured
+func webAppURL(pc *providerConfig, path string, args ...interface{}) string {
+	if pc.account == "" {
+		return ""
+	}
+	base, err := url.Parse(pc.baseURL)
+	if err != nil || base.Host == "" {
+		return ""
+	}
+	if strings.HasPrefix(base.Host, "api.") {
+		base.Host = "app." + strings.TrimPrefix(base.Host, "api.")
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/") + "/" + pc.account + fmt.Sprintf(path, args...)
+	base.RawQuery = ""
+	return base.String()
+}
+
+// flattenMetadata maps the metadata of a resource to the schema keys of
+// metadataSchema
+func flattenMetadata(meta resourceMetadata, webURL string) map[string]interface{} {
+	return map[string]interface{}{
+		"created_at": formatTimestamp(meta.CreatedAt),
+		"updated_at": formatTimestamp(meta.UpdatedAt),
+		"created_by": meta.CreatedBy,
+		"url":        webURL,
+	}
+}
+
+func setMetadata(d *schema.ResourceData, meta resourceMetadata, webURL string, diags *diag.Diagnostics) {
+	for key, value := range flattenMetadata(meta, webURL) {
+		appendError(d.Set(key, value), diags)
+	}
+}
+
+// withMetadata returns a copy of the schema with the computed metadata
+// attributes added to it
+func withMetadata(s map[string]*schema.Schema) map[string]*schema.Schema {
+	withMeta := make(map[string]*schema.Schema, len(s)+4)
+	for k, v := range s {
+		withMeta[k] = v
+	}
+	for _, k := range []string{"created_at", "updated_at", "created_by", "url"} {
+		withMeta[k] = &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		}
+	}
+	return withMeta
+}
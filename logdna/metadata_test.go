@@ -0,0 +1,69 @@
+package logdna
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata_apiTimestamp(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		`{"createdAt": 1600000000}`:                    "2020-09-13T12:26:40Z",
+		`{"createdAt": 1600000000123}`:                 "2020-09-13T12:26:40Z",
+		`{"createdAt": "1600000000"}`:                  "2020-09-13T12:26:40Z",
+		`{"createdAt": "2021-01-02T03:04:05.6+01:00"}`: "2021-01-02T02:04:05Z",
+		`{"createdAt": null}`:                          "",
+		`{}`:                                           "",
+	}
+	for body, expected := range cases {
+		meta := resourceMetadata{}
+		assert.Nil(json.Unmarshal([]byte(body), &meta), body)
+		assert.Equal(expected, formatTimestamp(meta.CreatedAt), body)
+	}
+
+	meta := resourceMetadata{}
+	assert.NotNil(json.Unmarshal([]byte(`{"createdAt": "yesterday"}`), &meta), "Unknown formats are an error")
+	assert.NotNil(json.Unmarshal([]byte(`{"createdAt": true}`), &meta), "Unknown types are an error")
+}
+
+func TestMetadata_webAppURL(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := &providerConfig{baseURL: "https://api.logdna.com", account: "a1b2c3d4e5"}
+	assert.Equal("https://app.logdna.com/a1b2c3d4e5/logs/view/abc123", webAppURL(pc, webPathView, "abc123"))
+	assert.Equal("https://app.logdna.com/a1b2c3d4e5/manage/categories/views/a%20b", webAppURL(pc, webPathCategory, "views", "a b"))
+
+	pc = &providerConfig{baseURL: "https://api.eu.logdna.com/", account: "a1b2c3d4e5"}
+	assert.Equal("https://app.eu.logdna.com/a1b2c3d4e5/manage/alerts/def", webAppURL(pc, webPathAlert, "def"), "The region is kept")
+
+	pc = &providerConfig{baseURL: "http://localhost:8080", account: "a1b2c3d4e5"}
+	assert.Equal("http://localhost:8080/a1b2c3d4e5/manage/usage/exclusion-rules/x", webAppURL(pc, webPathIngestionExclusion, "x"), "Other hosts are kept")
+
+	pc = &providerConfig{baseURL: "not a url", account: "a1b2c3d4e5"}
+	assert.Equal("", webAppURL(pc, webPathView, "abc123"))
+
+	pc = &providerConfig{baseURL: "https://api.logdna.com"}
+	assert.Equal("", webAppURL(pc, webPathView, "abc123"), "No link without an account")
+}
+
+func TestMetadata_flattenMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	view := viewResponse{}
+	assert.Nil(json.Unmarshal([]byte(`{"viewID": "abc", "createdAt": 1600000000, "createdBy": "me@logdna.com"}`), &view))
+	assert.Equal(map[string]interface{}{
+		"created_at": "2020-09-13T12:26:40Z",
+		"updated_at": "",
+		"created_by": "me@logdna.com",
+		"url":        "https://app.logdna.com/logs/view/abc",
+	}, flattenMetadata(view.resourceMetadata, "https://app.logdna.com/logs/view/abc"))
+
+	rule := exclusionRule{Title: "test", resourceMetadata: resourceMetadata{CreatedAt: &apiTimestamp{time.Unix(1600000000, 0)}}}
+	body, err := json.Marshal(rule.request())
+	assert.Nil(err)
+	assert.NotContains(string(body), "createdAt", "Metadata is never sent")
+}
@@ -10,6 +10,7 @@ import (
 type providerConfig struct {
 	serviceKey string
 	baseURL    string
+	account    string
 	httpClient *http.Client
 }
 
@@ -26,18 +27,32 @@ func Provider() *schema.Provider {
 				Optional: true,
 				Default:  "https://api.logdna.com",
 			},
+			"account": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LOGDNA_ACCOUNT", ""),
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"logdna_alert": dataSourceAlert(),
+			"logdna_alert":             dataSourceAlert(),
+			"logdna_archive":           dataSourceArchive(),
+			"logdna_exclusion_preview": dataSourceExclusionPreview(),
+			"logdna_stream_config":     dataSourceStreamConfig(),
+			"logdna_view":              dataSourceView(),
+			"logdna_views":             dataSourceViews(),
+			"logdna_webhook_preview":   dataSourceWebhookPreview(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"logdna_alert":               resourceAlert(),
-			"logdna_view":                resourceView(),
-			"logdna_category":            resourceCategory(),
-			"logdna_stream_config":       resourceStreamConfig(),
-			"logdna_stream_exclusion":    resourceStreamExclusion(),
-			"logdna_ingestion_exclusion": resourceIngestionExclusion(),
-			"logdna_archive":             resourceArchiveConfig(),
+			"logdna_alert":                   resourceAlert(),
+			"logdna_view":                    resourceView(),
+			"logdna_view_set":                resourceViewSet(),
+			"logdna_category":                resourceCategory(),
+			"logdna_stream_config":           resourceStreamConfig(),
+			"logdna_stream_exclusion":        resourceStreamExclusion(),
+			"logdna_stream_exclusion_set":    resourceStreamExclusionSet(),
+			"logdna_ingestion_exclusion":     resourceIngestionExclusion(),
+			"logdna_ingestion_exclusion_set": resourceIngestionExclusionSet(),
+			"logdna_archive":                 resourceArchiveConfig(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
@@ -46,10 +61,12 @@ func Provider() *schema.Provider {
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	serviceKey := d.Get("servicekey").(string)
 	url := d.Get("url").(string)
+	account := d.Get("account").(string)
 
 	return &providerConfig{
 		serviceKey: serviceKey,
 		baseURL:    url,
+		account:    account,
 		httpClient: &http.Client{Timeout: 15 * time.Second},
 	}, nil
 }
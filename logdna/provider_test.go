@@ -2,6 +2,7 @@ package logdna
 
 import (
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -9,6 +10,7 @@ import (
 
 var serviceKey = os.Getenv("SERVICE_KEY")
 var apiHostUrl = os.Getenv("API_URL")
+var accountID = os.Getenv("LOGDNA_ACCOUNT")
 var testAccProviders map[string]*schema.Provider
 var testAccProvider *schema.Provider
 
@@ -28,3 +30,12 @@ func TestProvider(t *testing.T) {
 func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
+
+// webURLPattern matches the web app link of a resource at `path`, which is
+// only set when the account of the service key is configured
+func webURLPattern(path string) *regexp.Regexp {
+	if accountID == "" {
+		return regexp.MustCompile(`^$`)
+	}
+	return regexp.MustCompile("/" + regexp.QuoteMeta(accountID) + path)
+}
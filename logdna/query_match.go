@@ -0,0 +1,234 @@
+package logdna
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This evaluates a parsed query against a log line, the way LogDNA search
+// does for the cases the provider needs to simulate (see exclusion previews):
+//
+//   - terms match the message case-insensitively, anywhere in it. `*` matches
+//     any run of non-space characters, and quoted phrases match as written.
+//   - `field:value` compares the whole value of `app`, `host`, `level` or of a
+//     meta field case-insensitively, with `*` wildcards. Values starting with
+//     a comparison operator, and ranges, compare numbers when both sides are
+//     numbers, and strings otherwise.
+//   - `field:(...)` applies every term of the group to the field.
+
+// queryLine is a log line and the fields a query can refer to
+type queryLine struct {
+	text  string
+	app   string
+	host  string
+	level string
+	meta  map[string]string
+}
+
+// field returns the value of a field of the line. Meta fields can be named
+// with or without their `meta.` prefix.
+func (l queryLine) field(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "app":
+		return l.app, l.app != ""
+	case "host":
+		return l.host, l.host != ""
+	case "level":
+		return l.level, l.level != ""
+	}
+	if v, ok := l.meta[name]; ok {
+		return v, true
+	}
+	v, ok := l.meta[strings.TrimPrefix(name, "meta.")]
+	return v, ok
+}
+
+// matchQuery reports whether the line matches the query. A nil query, i.e. an
+// empty one, matches every line.
+func matchQuery(node *queryNode, line queryLine) bool {
+	if node == nil {
+		return true
+	}
+	switch node.typ {
+	case queryNodeTerm:
+		return matchQueryTerm(node.text, line.text)
+	case queryNodeField:
+		if len(node.children) > 0 {
+			return matchQueryFieldGroup(node.field, node.children[0], line)
+		}
+		return matchQueryField(node.field, node.text, line)
+	case queryNodeNot:
+		return !matchQuery(node.children[0], line)
+	case queryNodeAnd:
+		for _, child := range node.children {
+			if !matchQuery(child, line) {
+				return false
+			}
+		}
+		return true
+	case queryNodeOr:
+		for _, child := range node.children {
+			if matchQuery(child, line) {
+				return true
+			}
+		}
+		return false
+	case queryNodeGroup:
+		return matchQuery(node.children[0], line)
+	}
+	return false
+}
+
+// matchQueryFieldGroup evaluates `field:(...)`, where the terms of the group
+// are values of the field
+func matchQueryFieldGroup(field string, node *queryNode, line queryLine) bool {
+	switch node.typ {
+	case queryNodeTerm:
+		return matchQueryField(field, node.text, line)
+	case queryNodeNot:
+		return !matchQueryFieldGroup(field, node.children[0], line)
+	case queryNodeAnd:
+		for _, child := range node.children {
+			if !matchQueryFieldGroup(field, child, line) {
+				return false
+			}
+		}
+		return true
+	case queryNodeOr:
+		for _, child := range node.children {
+			if matchQueryFieldGroup(field, child, line) {
+				return true
+			}
+		}
+		return false
+	case queryNodeGroup:
+		return matchQueryFieldGroup(field, node.children[0], line)
+	}
+	// Other fields inside of the group refer to themselves
+	return matchQuery(node, line)
+}
+
+func matchQueryTerm(term, text string) bool {
+	if isQueryPhrase(term) {
+		return strings.Contains(strings.ToLower(text), strings.ToLower(unquoteQueryPhrase(term)))
+	}
+	return wildcardPattern(term, false).MatchString(text)
+}
+
+func matchQueryField(field, value string, line queryLine) bool {
+	actual, ok := line.field(field)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case isQueryPhrase(value):
+		return strings.EqualFold(actual, unquoteQueryPhrase(value))
+	case strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{"):
+		return matchQueryRange(value, actual)
+	case strings.HasPrefix(value, ">") || strings.HasPrefix(value, "<"):
+		operand := strings.TrimLeft(value, "<>=")
+		cmp := compareQueryValues(actual, unescapeQuery(operand))
+		switch value[:len(value)-len(operand)] {
+		case ">":
+			return cmp > 0
+		case ">=":
+			return cmp >= 0
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		}
+		return false
+	}
+	return wildcardPattern(value, true).MatchString(actual)
+}
+
+// matchQueryRange evaluates `[from TO to]`, where curly brackets exclude their
+// bound and `*` leaves it open
+func matchQueryRange(value, actual string) bool {
+	bounds := strings.Fields(value[1 : len(value)-1])
+	from, to := bounds[0], bounds[2]
+	if from != "*" {
+		cmp := compareQueryValues(actual, from)
+		if cmp < 0 || (cmp == 0 && value[0] == '{') {
+			return false
+		}
+	}
+	if to != "*" {
+		cmp := compareQueryValues(actual, to)
+		if cmp > 0 || (cmp == 0 && value[len(value)-1] == '}') {
+			return false
+		}
+	}
+	return true
+}
+
+// compareQueryValues compares numerically when both values are numbers
+func compareQueryValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// wildcardPattern compiles a value with `*` wildcards, matching either the
+// whole string or anywhere in it. Escaped characters, e.g. `\*`, are literal.
+func wildcardPattern(value string, whole bool) *regexp.Regexp {
+	wildcard := `\S*`
+	if whole {
+		wildcard = ".*"
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case r == '\\' && !escaped:
+			escaped = true
+			continue
+		case r == '*' && !escaped:
+			b.WriteString(wildcard)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+		escaped = false
+	}
+
+	if whole {
+		return regexp.MustCompile("(?is)^" + b.String() + "$")
+	}
+	return regexp.MustCompile("(?i)" + b.String())
+}
+
+func isQueryPhrase(value string) bool {
+	return len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)
+}
+
+func unquoteQueryPhrase(value string) string {
+	return unescapeQuery(value[1 : len(value)-1])
+}
+
+// unescapeQuery removes the backslashes that escape a character
+func unescapeQuery(value string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
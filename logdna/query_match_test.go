@@ -0,0 +1,76 @@
+package logdna
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryMatch_matchQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	line := queryLine{
+		text:  `GET /health 200 "connection reset" by peer`,
+		app:   "nginx",
+		host:  "web-1",
+		level: "INFO",
+		meta:  map[string]string{"response": "200", "duration": "42.5", "path": `C:\logs`, "user.id": "abc"},
+	}
+
+	cases := map[string]bool{
+		"":                                  true,
+		"health":                            true,
+		"HEALTH":                            true,
+		"ealt":                              true,
+		"missing":                           false,
+		"he*th":                             true,
+		"/health GET":                       true,
+		`"connection reset"`:                true,
+		`"reset connection"`:                false,
+		"missing OR health":                 true,
+		"NOT health":                        false,
+		"-missing":                          true,
+		"app:nginx":                         true,
+		"app:NGINX":                         true,
+		"app:ngin":                          false,
+		"app:ng*":                           true,
+		"host:web-*":                        true,
+		"level:info":                        true,
+		"level:(error OR info)":             true,
+		"level:(error OR fatal)":            false,
+		"level:(NOT debug)":                 true,
+		"response:200":                      true,
+		"meta.response:200":                 true,
+		"response:>=200":                    true,
+		"response:>200":                     false,
+		"response:(>=200 <300)":             true,
+		"response:[200 TO 299]":             true,
+		"response:{200 TO 299]":             false,
+		"response:[* TO 199]":               false,
+		"duration:<100":                     true,
+		"user.id:abc":                       true,
+		`path:C\:\\logs`:                    true,
+		"unknown:value":                     false,
+		"NOT (level:debug OR level:trace)":  true,
+		"app:nginx (health OR status) -foo": true,
+		"app:nginx AND level:debug":         false,
+	}
+	for query, expected := range cases {
+		node, err := parseQuery(query)
+		assert.Nil(err, query)
+		assert.Equal(expected, matchQuery(node, line), query)
+	}
+}
+
+func TestQueryMatch_wildcardPattern(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(wildcardPattern("web-*", true).MatchString("web-1"))
+	assert.False(wildcardPattern("web-*", true).MatchString("api-web-1"))
+	assert.True(wildcardPattern("err*", false).MatchString("an error occurred"))
+	assert.False(wildcardPattern("an*occurred", false).MatchString("an error occurred"), "Terms do not span words")
+	assert.True(wildcardPattern(`a\*b`, true).MatchString("a*b"), "Escaped wildcards are literal")
+	assert.False(wildcardPattern(`a\*b`, true).MatchString("axxb"))
+	assert.True(wildcardPattern("a.b", true).MatchString("a.b"))
+	assert.False(wildcardPattern("a.b", true).MatchString("axb"), "Other characters are literal")
+}
@@ -0,0 +1,459 @@
+package logdna
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// This is an offline parser for the LogDNA search syntax, used to catch
+// mistakes at plan time rather than at apply time (or never, for a query that
+// silently matches nothing). See https://docs.logdna.com/docs/search
+//
+//	query   := or
+//	or      := and (("OR" | "||") and)*
+//	and     := unary (("AND" | "&&")? unary)*
+//	unary   := ("NOT" | "!" | "-") unary | primary
+//	primary := "(" or ")" | term | field
+//	field   := name ":" (word | phrase | range | "(" or ")")
+//	range   := ("[" | "{") word "TO" word ("]" | "}")
+//
+// Words may contain `*` wildcards, and field values may start with a
+// comparison operator (`>`, `>=`, `<`, `<=`), e.g. `response:>=500`.
+
+type queryTokenType int
+
+const (
+	queryTokenEOF queryTokenType = iota
+	queryTokenLParen
+	queryTokenRParen
+	queryTokenAnd
+	queryTokenOr
+	queryTokenNot
+	queryTokenTerm
+	queryTokenField
+)
+
+type queryToken struct {
+	typ  queryTokenType
+	text string // as written, for error messages
+	pos  int    // 1-based position of the first character
+
+	// Only for fields. `value` is empty when the field is followed by a group.
+	field string
+	value string
+}
+
+type queryNodeType int
+
+const (
+	queryNodeTerm queryNodeType = iota
+	queryNodeField
+	queryNodeNot
+	queryNodeAnd
+	queryNodeOr
+	queryNodeGroup
+)
+
+type queryNode struct {
+	typ      queryNodeType
+	text     string // term, or field value
+	field    string
+	children []*queryNode
+}
+
+// queryError is a syntax error at a given position of the query
+type queryError struct {
+	pos int
+	msg string
+}
+
+func (e *queryError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.msg, e.pos)
+}
+
+func queryErrorf(pos int, format string, args ...interface{}) error {
+	return &queryError{pos: pos, msg: fmt.Sprintf(format, args...)}
+}
+
+// String renders the node in its normalized form: single spaces between terms,
+// upper case operators and `AND` left implicit since it is the default
+func (n *queryNode) String() string {
+	switch n.typ {
+	case queryNodeField:
+		if len(n.children) > 0 {
+			return fmt.Sprintf("%s:%s", n.field, n.children[0])
+		}
+		return fmt.Sprintf("%s:%s", n.field, n.text)
+	case queryNodeNot:
+		return fmt.Sprintf("NOT %s", n.children[0])
+	case queryNodeAnd, queryNodeOr:
+		parts := make([]string, 0, len(n.children))
+		for _, child := range n.children {
+			parts = append(parts, child.String())
+		}
+		if n.typ == queryNodeOr {
+			return strings.Join(parts, " OR ")
+		}
+		return strings.Join(parts, " ")
+	case queryNodeGroup:
+		return fmt.Sprintf("(%s)", n.children[0])
+	default:
+		return n.text
+	}
+}
+
+type queryLexer struct {
+	input []rune
+	idx   int
+}
+
+func isQueryDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+func (l *queryLexer) peek(offset int) rune {
+	if l.idx+offset >= len(l.input) {
+		return 0
+	}
+	return l.input[l.idx+offset]
+}
+
+func (l *queryLexer) tokens() ([]queryToken, error) {
+	var tokens []queryToken
+	for {
+		for l.idx < len(l.input) && unicode.IsSpace(l.input[l.idx]) {
+			l.idx++
+		}
+		pos := l.idx + 1
+		if l.idx >= len(l.input) {
+			return append(tokens, queryToken{typ: queryTokenEOF, text: "end of query", pos: pos}), nil
+		}
+
+		switch r := l.input[l.idx]; {
+		case r == '(':
+			l.idx++
+			tokens = append(tokens, queryToken{typ: queryTokenLParen, text: "(", pos: pos})
+		case r == ')':
+			l.idx++
+			tokens = append(tokens, queryToken{typ: queryTokenRParen, text: ")", pos: pos})
+		case r == '"':
+			phrase, err := l.phrase()
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{typ: queryTokenTerm, text: phrase, pos: pos})
+		case r == '&' && l.peek(1) == '&':
+			l.idx += 2
+			tokens = append(tokens, queryToken{typ: queryTokenAnd, text: "&&", pos: pos})
+		case r == '|' && l.peek(1) == '|':
+			l.idx += 2
+			tokens = append(tokens, queryToken{typ: queryTokenOr, text: "||", pos: pos})
+		case r == '!' || r == '-':
+			l.idx++
+			tokens = append(tokens, queryToken{typ: queryTokenNot, text: string(r), pos: pos})
+		default:
+			tok, err := l.word()
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+}
+
+// phrase reads a quoted phrase, keeping the quotes and escapes as written
+func (l *queryLexer) phrase() (string, error) {
+	start := l.idx
+	l.idx++
+	for l.idx < len(l.input) {
+		switch l.input[l.idx] {
+		case '\\':
+			l.idx += 2
+			continue
+		case '"':
+			l.idx++
+			return string(l.input[start:l.idx]), nil
+		}
+		l.idx++
+	}
+	return "", queryErrorf(start+1, "unterminated quoted phrase")
+}
+
+// word reads a term, an operator or a `field:value` pair
+func (l *queryLexer) word() (queryToken, error) {
+	start := l.idx
+	pos := start + 1
+	for l.idx < len(l.input) && !isQueryDelimiter(l.input[l.idx]) {
+		switch l.input[l.idx] {
+		case '\\':
+			l.idx++
+		case ':':
+			if l.idx == start {
+				return queryToken{}, queryErrorf(pos, "missing field name before \":\"")
+			}
+			return l.fieldValue(string(l.input[start:l.idx]), pos)
+		}
+		l.idx++
+	}
+	if l.idx > len(l.input) {
+		return queryToken{}, queryErrorf(len(l.input), "nothing to escape after \"\\\"")
+	}
+
+	text := string(l.input[start:l.idx])
+	switch text {
+	case "AND":
+		return queryToken{typ: queryTokenAnd, text: text, pos: pos}, nil
+	case "OR":
+		return queryToken{typ: queryTokenOr, text: text, pos: pos}, nil
+	case "NOT":
+		return queryToken{typ: queryTokenNot, text: text, pos: pos}, nil
+	}
+	return queryToken{typ: queryTokenTerm, text: text, pos: pos}, nil
+}
+
+// fieldValue reads what follows `field:`, with the lexer positioned on the colon
+func (l *queryLexer) fieldValue(field string, pos int) (queryToken, error) {
+	l.idx++
+	tok := queryToken{typ: queryTokenField, field: field, pos: pos}
+	valuePos := l.idx + 1
+
+	switch r := l.peek(0); {
+	case r == 0 || unicode.IsSpace(r) || r == ')':
+		return tok, queryErrorf(valuePos, "missing value for field %q", field)
+	case r == '(':
+		// The group is parsed as a sub-query
+		tok.text = field + ":"
+		return tok, nil
+	case r == '"':
+		phrase, err := l.phrase()
+		if err != nil {
+			return tok, err
+		}
+		tok.value = phrase
+	case r == '[' || r == '{':
+		value, err := l.queryRange()
+		if err != nil {
+			return tok, err
+		}
+		tok.value = value
+	default:
+		start := l.idx
+		for l.idx < len(l.input) && !isQueryDelimiter(l.input[l.idx]) {
+			if l.input[l.idx] == '\\' {
+				l.idx++
+			}
+			l.idx++
+		}
+		if l.idx > len(l.input) {
+			l.idx = len(l.input)
+		}
+		tok.value = string(l.input[start:l.idx])
+
+		operand := strings.TrimLeft(tok.value, "<>=")
+		if operand == "" {
+			return tok, queryErrorf(valuePos, "missing value after %q for field %q", tok.value, field)
+		}
+		if op := tok.value[:len(tok.value)-len(operand)]; op != "" && op != ">" && op != ">=" && op != "<" && op != "<=" {
+			return tok, queryErrorf(valuePos, "invalid comparison operator %q for field %q", op, field)
+		}
+	}
+
+	tok.text = field + ":" + tok.value
+	return tok, nil
+}
+
+// queryRange reads `[from TO to]`, where either bracket may be curly for an
+// exclusive bound
+func (l *queryLexer) queryRange() (string, error) {
+	start := l.idx
+	for l.idx < len(l.input) {
+		r := l.input[l.idx]
+		l.idx++
+		if r != ']' && r != '}' {
+			continue
+		}
+
+		value := string(l.input[start:l.idx])
+		bounds := strings.Fields(value[1 : len(value)-1])
+		if len(bounds) != 3 || bounds[1] != "TO" {
+			return "", queryErrorf(start+1, "invalid range %q, expected [from TO to]", value)
+		}
+		// Normalize the whitespace inside of the brackets
+		return fmt.Sprintf("%c%s TO %s%c", value[0], bounds[0], bounds[2], r), nil
+	}
+	return "", queryErrorf(start+1, "unclosed range")
+}
+
+type queryParser struct {
+	tokens []queryToken
+	idx    int
+}
+
+func (p *queryParser) current() queryToken {
+	return p.tokens[p.idx]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.idx]
+	if tok.typ != queryTokenEOF {
+		p.idx++
+	}
+	return tok
+}
+
+func (p *queryParser) or() (*queryNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	node := &queryNode{typ: queryNodeOr, children: []*queryNode{left}}
+	for p.current().typ == queryTokenOr {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, right)
+	}
+	if len(node.children) == 1 {
+		return left, nil
+	}
+	return node, nil
+}
+
+func (p *queryParser) and() (*queryNode, error) {
+	first, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	node := &queryNode{typ: queryNodeAnd, children: []*queryNode{first}}
+	for {
+		switch p.current().typ {
+		case queryTokenAnd:
+			p.next()
+		case queryTokenTerm, queryTokenField, queryTokenNot, queryTokenLParen:
+			// Terms next to each other are implicitly joined by AND
+		default:
+			if len(node.children) == 1 {
+				return first, nil
+			}
+			return node, nil
+		}
+		child, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, child)
+	}
+}
+
+func (p *queryParser) unary() (*queryNode, error) {
+	if p.current().typ != queryTokenNot {
+		return p.primary()
+	}
+	op := p.next()
+	switch p.current().typ {
+	case queryTokenEOF, queryTokenRParen, queryTokenAnd, queryTokenOr:
+		return nil, queryErrorf(op.pos, "expected a term after %q", op.text)
+	}
+	child, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &queryNode{typ: queryNodeNot, children: []*queryNode{child}}, nil
+}
+
+func (p *queryParser) primary() (*queryNode, error) {
+	tok := p.next()
+	switch tok.typ {
+	case queryTokenTerm:
+		return &queryNode{typ: queryNodeTerm, text: tok.text}, nil
+	case queryTokenField:
+		node := &queryNode{typ: queryNodeField, field: tok.field, text: tok.value}
+		if tok.value == "" {
+			group, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			node.children = []*queryNode{group}
+		}
+		return node, nil
+	case queryTokenLParen:
+		if p.current().typ == queryTokenRParen {
+			return nil, queryErrorf(tok.pos, "empty group")
+		}
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.current().typ != queryTokenRParen {
+			return nil, queryErrorf(tok.pos, "missing \")\" to close \"(\"")
+		}
+		p.next()
+		return &queryNode{typ: queryNodeGroup, children: []*queryNode{inner}}, nil
+	case queryTokenEOF:
+		return nil, queryErrorf(tok.pos, "unexpected end of query")
+	default:
+		return nil, queryErrorf(tok.pos, "unexpected %q", tok.text)
+	}
+}
+
+// parseQuery parses a LogDNA search query. An empty query returns a nil node.
+func parseQuery(query string) (*queryNode, error) {
+	lexer := &queryLexer{input: []rune(query)}
+	tokens, err := lexer.tokens()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	if p.current().typ == queryTokenEOF {
+		return nil, nil
+	}
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.current(); tok.typ != queryTokenEOF {
+		return nil, queryErrorf(tok.pos, "unexpected %q", tok.text)
+	}
+	return node, nil
+}
+
+// normalizeQuery returns the normalized form of a query, so that queries that
+// only differ by whitespace or operator spelling compare as equal
+func normalizeQuery(query string) (string, error) {
+	node, err := parseQuery(query)
+	if err != nil || node == nil {
+		return "", err
+	}
+	return node.String(), nil
+}
+
+// validateQuery is a ValidateFunc for attributes holding a LogDNA search query
+func validateQuery(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := parseQuery(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q: invalid search query: %s", key, err))
+	}
+	return
+}
+
+// suppressQueryDiff ignores differences between queries with the same
+// normalized form
+func suppressQueryDiff(k, old, new string, d *schema.ResourceData) bool {
+	normalizedOld, err := normalizeQuery(old)
+	if err != nil {
+		return false
+	}
+	normalizedNew, err := normalizeQuery(new)
+	if err != nil {
+		return false
+	}
+	shouldSuppress := normalizedOld == normalizedNew
+	log.Printf("[DEBUG] Do the %q queries have the same normalized form? %t", k, shouldSuppress)
+	return shouldSuppress
+}
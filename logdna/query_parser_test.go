@@ -0,0 +1,92 @@
+package logdna
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryParser_normalizeQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		"":                                   "",
+		"   ":                                "",
+		"error":                              "error",
+		"  level:debug   my  query ":         "level:debug my query",
+		"query-foo AND query-bar":            "query-foo query-bar",
+		"a && b || c":                        "a b OR c",
+		"a or b":                             "a or b",
+		"-app:nginx":                         "NOT app:nginx",
+		"!host:web-1 NOT level:info":         "NOT host:web-1 NOT level:info",
+		`"connection  reset" AND app:api`:    `"connection  reset" app:api`,
+		`message:"timed out"`:                `message:"timed out"`,
+		"( a OR b )  c":                      "(a OR b) c",
+		"response:[400   TO 499]":            "response:[400 TO 499]",
+		"duration:{100 TO 500]":              "duration:{100 TO 500]",
+		"response:>=500 duration:<10":        "response:>=500 duration:<10",
+		"app:web* host:*":                    "app:web* host:*",
+		"level:(error OR fatal)":             "level:(error OR fatal)",
+		"time:10:00":                         "time:10:00",
+		`path:C\:\\logs`:                     `path:C\:\\logs`,
+		`"escaped \" quote"`:                 `"escaped \" quote"`,
+		"NOT (level:debug OR level:trace) x": "NOT (level:debug OR level:trace) x",
+		"response:(>=200 <300) request:*":    "response:(>=200 <300) request:*",
+		"robots.txt OR .well-known":          "robots.txt OR .well-known",
+	}
+	for query, expected := range cases {
+		normalized, err := normalizeQuery(query)
+		assert.Nil(err, query)
+		assert.Equal(expected, normalized, query)
+	}
+}
+
+func TestQueryParser_errors(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		"(a OR b":            `missing ")" to close "(" at position 1`,
+		"a OR b)":            `unexpected ")" at position 7`,
+		"a OR":               "unexpected end of query at position 5",
+		"OR a":               `unexpected "OR" at position 1`,
+		"a AND AND b":        `unexpected "AND" at position 7`,
+		"a ()":               "empty group at position 3",
+		`"unterminated`:      "unterminated quoted phrase at position 1",
+		`level:"error`:       "unterminated quoted phrase at position 7",
+		"level: error":       `missing value for field "level" at position 7`,
+		":error":             `missing field name before ":" at position 1`,
+		"a -":                `expected a term after "-" at position 3`,
+		"NOT OR a":           `expected a term after "NOT" at position 1`,
+		"response:[400 499]": `invalid range "[400 499]", expected [from TO to] at position 10`,
+		"response:[400 TO":   "unclosed range at position 10",
+		"response:>=":        `missing value after ">=" for field "response" at position 10`,
+		"response:=>5":       `invalid comparison operator "=>" for field "response" at position 10`,
+		`error\`:             `nothing to escape after "\" at position 6`,
+	}
+	for query, expected := range cases {
+		_, err := parseQuery(query)
+		if assert.NotNil(err, query) {
+			assert.Equal(expected, err.Error(), query)
+		}
+	}
+}
+
+func TestQueryParser_validateQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	_, errs := validateQuery("level:error (app:api OR app:web)", "query")
+	assert.Empty(errs, "No errors")
+
+	_, errs = validateQuery("level:error (app:api", "query")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.EqualError(errs[0], `"query": invalid search query: missing ")" to close "(" at position 13`)
+}
+
+func TestQueryParser_suppressQueryDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(suppressQueryDiff("query", "a AND  b", "a b", nil), "Whitespace and implicit AND are suppressed")
+	assert.True(suppressQueryDiff("query", "-a", "NOT a", nil), "Negation spelling is suppressed")
+	assert.False(suppressQueryDiff("query", "a b", "a OR b", nil), "Operators are a diff")
+	assert.False(suppressQueryDiff("query", "(a", "(a", nil), "Invalid queries are never suppressed")
+}
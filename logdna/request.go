@@ -3,6 +3,7 @@ package logdna
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -76,7 +77,26 @@ func (c *requestConfig) MakeRequest() ([]byte, error) {
 		return nil, fmt.Errorf("error parsing HTTP response: %s, %s", err, string(body))
 	}
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s %s, status %d NOT OK! %s", c.method, c.apiURL, res.StatusCode, string(body))
+		return nil, &statusError{method: c.method, apiURL: c.apiURL, statusCode: res.StatusCode, body: string(body)}
 	}
 	return body, err
 }
+
+// statusError is returned by MakeRequest when the status of the response is
+// not 200 OK
+type statusError struct {
+	method     string
+	apiURL     string
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s, status %d NOT OK! %s", e.method, e.apiURL, e.statusCode, e.body)
+}
+
+// isNotFound reports whether the request failed because there is no such resource
+func isNotFound(err error) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.statusCode == http.StatusNotFound
+}
@@ -204,6 +204,28 @@ func TestRequest_MakeRequest(t *testing.T) {
 			strings.Contains(err.Error(), "status 400 NOT OK!"),
 			"Expected error message",
 		)
+		assert.False(isNotFound(err), "Only 404 is not found")
+	})
+
+	t.Run("Reports 404 errors as not found", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+		}))
+		defer ts.Close()
+
+		pc.baseURL = ts.URL
+
+		req := newRequestConfig(
+			&pc,
+			"GET",
+			fmt.Sprintf("/someapi/%s", resourceID),
+			nil,
+		)
+
+		_, err := req.MakeRequest()
+		assert.Error(err, "Expected error")
+		assert.True(isNotFound(err), "Expected a not found error")
+		assert.False(isNotFound(fmt.Errorf("status 404")), "Other errors are not checked")
 	})
 
 	t.Run("Handles errors when creating a new HTTP request", func(t *testing.T) {
@@ -19,6 +19,7 @@ type viewRequest struct {
 	Hosts    []string         `json:"hosts,omitempty"`
 	Levels   []string         `json:"levels,omitempty"`
 	Name     string           `json:"name,omitempty"`
+	PresetID string           `json:"presetid,omitempty"`
 	Query    string           `json:"query,omitempty"`
 	Tags     []string         `json:"tags,omitempty"`
 }
@@ -29,19 +30,23 @@ type alertRequest struct {
 }
 
 type channelRequest struct {
-	BodyTemplate    map[string]interface{} `json:"bodyTemplate,omitempty"`
-	Emails          []string               `json:"emails,omitempty"`
-	Headers         map[string]string      `json:"headers,omitempty"`
-	Immediate       string                 `json:"immediate,omitempty"`
-	Integration     string                 `json:"integration,omitempty"`
-	Key             string                 `json:"key,omitempty"`
-	Method          string                 `json:"method,omitempty"`
-	Operator        string                 `json:"operator,omitempty"`
-	Terminal        string                 `json:"terminal,omitempty"`
-	TriggerInterval string                 `json:"triggerinterval,omitempty"`
-	TriggerLimit    int                    `json:"triggerlimit,omitempty"`
-	Timezone        string                 `json:"timezone,omitempty"`
-	URL             string                 `json:"url,omitempty"`
+	BodyFormat      string            `json:"bodyFormat,omitempty"`
+	BodyTemplate    interface{}       `json:"bodyTemplate,omitempty"`
+	Emails          []string          `json:"emails,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Immediate       string            `json:"immediate,omitempty"`
+	Integration     string            `json:"integration,omitempty"`
+	Key             string            `json:"key,omitempty"`
+	Method          string            `json:"method,omitempty"`
+	Operator        string            `json:"operator,omitempty"`
+	Terminal        string            `json:"terminal,omitempty"`
+	TriggerInterval string            `json:"triggerinterval,omitempty"`
+	TriggerLimit    int               `json:"triggerlimit,omitempty"`
+	Timezone        string            `json:"timezone,omitempty"`
+	URL             string            `json:"url,omitempty"`
+
+	// Settings of a `raw_channel`, sent as-is along with the integration
+	raw map[string]interface{}
 }
 
 type categoryRequest struct {
@@ -56,16 +61,19 @@ func (view *viewRequest) CreateRequestBody(d *schema.ResourceData) diag.Diagnost
 	// Scalars
 	view.Name = d.Get("name").(string)
 	view.Query = d.Get("query").(string)
+	view.PresetID = d.Get("presetid").(string)
 
 	// Simple arrays
 	view.Apps = listToStrings(d.Get("apps").([]interface{}))
 	view.Category = listToStrings(d.Get("categories").([]interface{}))
 	view.Hosts = listToStrings(d.Get("hosts").([]interface{}))
-	view.Levels = listToStrings(d.Get("levels").([]interface{}))
+	view.Levels = canonicalViewLevels(listToStrings(d.Get("levels").([]interface{})))
 	view.Tags = listToStrings(d.Get("tags").([]interface{}))
 
-	// Complex array interfaces
-	view.Channels = *aggregateAllChannelsFromSchema(d, &diags)
+	// Complex array interfaces. Channels come from the preset alert when one is attached.
+	if view.PresetID == "" {
+		view.Channels = *aggregateAllChannelsFromSchema(d, &diags)
+	}
 
 	return diags
 }
@@ -133,6 +141,15 @@ func aggregateAllChannelsFromSchema(
 		)...,
 	)
 
+	allChannelEntries = append(
+		allChannelEntries,
+		*iterateIntegrationType(
+			rawChannelsFromSchema(d),
+			RAW,
+			diags,
+		)...,
+	)
+
 	return &allChannelEntries
 }
 
@@ -160,6 +177,8 @@ func iterateIntegrationType(
 			prepared = slackChannelRequest(e)
 		case WEBHOOK:
 			prepared = webHookChannelRequest(e, diags)
+		case RAW:
+			prepared = rawChannelRequest(e, diags)
 		default:
 			*diags = append(*diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -242,17 +261,25 @@ func webHookChannelRequest(s map[string]interface{}, diags *diag.Diagnostics) ch
 		Terminal:        s["terminal"].(string),
 	}
 
+	format, _ := s["body_format"].(string)
+	if format == "" {
+		format = bodyFormatJSON
+	}
+	c.BodyFormat = format
+
 	if bodyTemplate := s["bodytemplate"].(string); bodyTemplate != "" {
-		var bt map[string]interface{}
-		// See if the JSON is valid, but don't use the value or it will double encode
-		err := json.Unmarshal([]byte(bodyTemplate), &bt)
+		bt, err := prepareWebhookBody(format, bodyTemplate)
 
 		if err == nil {
 			c.BodyTemplate = bt
 		} else {
+			summary := "bodytemplate is not a valid JSON string"
+			if format == bodyFormatForm {
+				summary = "bodytemplate is not a valid form-urlencoded string"
+			}
 			*diags = append(*diags, diag.Diagnostic{
 				Severity: diag.Error,
-				Summary:  "bodytemplate is not a valid JSON string",
+				Summary:  summary,
 				Detail:   err.Error(),
 			})
 		}
@@ -261,6 +288,68 @@ func webHookChannelRequest(s map[string]interface{}, diags *diag.Diagnostics) ch
 	return c
 }
 
+// rawChannelsFromSchema returns the declared `raw_channel` blocks. When unknown
+// channels are kept, the ones only found in state are added so that a PUT does
+// not delete them.
+func rawChannelsFromSchema(d *schema.ResourceData) []interface{} {
+	old, new := d.GetChange("raw_channel")
+	rawChannels := new.([]interface{})
+
+	if d.Get("unknown_channels").(string) != unknownChannelsKeep {
+		return rawChannels
+	}
+
+	declared := make(map[string]int)
+	for _, c := range rawChannels {
+		declared[channelFingerprint(RAW, c.(map[string]interface{}))]++
+	}
+	for _, c := range old.([]interface{}) {
+		fp := channelFingerprint(RAW, c.(map[string]interface{}))
+		if declared[fp] > 0 {
+			declared[fp]--
+			continue
+		}
+		rawChannels = append(rawChannels, c)
+	}
+	return rawChannels
+}
+
+func rawChannelRequest(s map[string]interface{}, diags *diag.Diagnostics) interface{} {
+	integration := s["integration"].(string)
+	settings := make(map[string]interface{})
+
+	if raw := s["settings"].(string); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+			*diags = append(*diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "settings is not a valid JSON object",
+				Detail:   fmt.Sprintf("raw_channel %s: %s", integration, err),
+			})
+			return nil
+		}
+	}
+
+	return channelRequest{
+		Integration: integration,
+		raw:         settings,
+	}
+}
+
+// MarshalJSON sends raw channels with their settings as top level keys
+func (c channelRequest) MarshalJSON() ([]byte, error) {
+	type plain channelRequest
+	if c.raw == nil {
+		return json.Marshal(plain(c))
+	}
+
+	body := make(map[string]interface{}, len(c.raw)+1)
+	for k, v := range c.raw {
+		body[k] = v
+	}
+	body["integration"] = c.Integration
+	return json.Marshal(body)
+}
+
 func listToStrings(list []interface{}) []string {
 	strs := make([]string, 0, len(list))
 	for _, elem := range list {
@@ -1,9 +1,13 @@
 package logdna
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,3 +33,138 @@ func TestRequestTypes_iterateIntegrationType(t *testing.T) {
 		assert.Equal("Unrecognized integration: NOPE", err.Detail, "Detail")
 	})
 }
+
+func TestRequestTypes_webHookChannelRequest(t *testing.T) {
+	assert := assert.New(t)
+	channel := func(format, body string) map[string]interface{} {
+		return map[string]interface{}{
+			"body_format":     format,
+			"bodytemplate":    body,
+			"headers":         map[string]interface{}{},
+			"immediate":       "false",
+			"method":          "post",
+			"operator":        "presence",
+			"terminal":        "true",
+			"triggerinterval": "15m",
+			"triggerlimit":    15,
+			"url":             "https://yourwebhook/endpoint",
+		}
+	}
+
+	t.Run("A JSON array body is sent as JSON", func(t *testing.T) {
+		var diags diag.Diagnostics
+		c := webHookChannelRequest(channel(bodyFormatJSON, `["{{ name }}"]`), &diags)
+		assert.False(diags.HasError(), "No errors")
+
+		encoded, err := json.Marshal(c)
+		assert.Nil(err, "No errors")
+		assert.Contains(string(encoded), `"bodyFormat":"json","bodyTemplate":["{{ name }}"]`)
+	})
+
+	t.Run("A text body is sent as a string", func(t *testing.T) {
+		var diags diag.Diagnostics
+		c := webHookChannelRequest(channel(bodyFormatText, "Alert from {{ name }}"), &diags)
+		assert.False(diags.HasError(), "No errors")
+
+		encoded, err := json.Marshal(c)
+		assert.Nil(err, "No errors")
+		assert.Contains(string(encoded), `"bodyFormat":"text","bodyTemplate":"Alert from {{ name }}"`)
+	})
+
+	t.Run("An invalid form body is an error", func(t *testing.T) {
+		var diags diag.Diagnostics
+		webHookChannelRequest(channel(bodyFormatForm, "a=%zz"), &diags)
+		assert.True(diags.HasError(), "There was an error")
+		assert.Equal("bodytemplate is not a valid form-urlencoded string", diags[0].Summary, "Summary")
+	})
+}
+
+func TestRequestTypes_rawChannelsFromSchema(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceView()
+	state := &terraform.InstanceState{
+		ID: "abc123",
+		Attributes: map[string]string{
+			"id":                        "abc123",
+			"name":                      "test",
+			"query":                     "test",
+			"unknown_channels":          unknownChannelsKeep,
+			"manage_channels":           manageChannelsAuthoritative,
+			"raw_channel.#":             "1",
+			"raw_channel.0.integration": "opsgenie",
+			"raw_channel.0.settings":    `{"key":"secret"}`,
+			"email_channel.#":           "0",
+			"pagerduty_channel.#":       "0",
+			"slack_channel.#":           "0",
+			"webhook_channel.#":         "0",
+			"apps.#":                    "0",
+			"categories.#":              "0",
+			"hosts.#":                   "0",
+			"levels.#":                  "0",
+			"tags.#":                    "0",
+		},
+	}
+	dataFor := func(config map[string]interface{}) (*schema.ResourceData, *terraform.InstanceDiff) {
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+		assert.Nil(err, "No errors")
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(err, "No errors")
+		return d, diff
+	}
+
+	t.Run("Undeclared unknown channels are not a diff and are sent on update", func(t *testing.T) {
+		d, diff := dataFor(map[string]interface{}{"name": "test", "query": "updated"})
+		_, rawChanged := diff.Attributes["raw_channel.#"]
+		assert.False(rawChanged, "raw_channel has no diff")
+
+		view := viewRequest{}
+		assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+		encoded, err := json.Marshal(view.Channels)
+		assert.Nil(err, "No errors")
+		assert.Equal(`[{"integration":"opsgenie","key":"secret"}]`, string(encoded))
+	})
+
+	t.Run("Declaring a new raw channel keeps the undeclared ones", func(t *testing.T) {
+		d, _ := dataFor(map[string]interface{}{
+			"name":  "test",
+			"query": "test",
+			"raw_channel": []interface{}{
+				map[string]interface{}{"integration": "victorops", "settings": `{"url":"https://victorops"}`},
+			},
+		})
+
+		view := viewRequest{}
+		assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+		encoded, err := json.Marshal(view.Channels)
+		assert.Nil(err, "No errors")
+		assert.Equal(
+			`[{"integration":"victorops","url":"https://victorops"},{"integration":"opsgenie","key":"secret"}]`,
+			string(encoded),
+		)
+	})
+
+	t.Run("Undeclared unknown channels are removed when asked to", func(t *testing.T) {
+		d, diff := dataFor(map[string]interface{}{"name": "test", "query": "test", "unknown_channels": unknownChannelsRemove})
+		assert.Equal("0", diff.Attributes["raw_channel.#"].New, "raw_channel is planned for removal")
+
+		view := viewRequest{}
+		assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+		assert.Empty(view.Channels, "No channels are sent")
+	})
+}
+
+func TestRequestTypes_viewRequestPresetID(t *testing.T) {
+	assert := assert.New(t)
+
+	d := schema.TestResourceDataRaw(t, resourceView().Schema, map[string]interface{}{
+		"name":     "test",
+		"query":    "test",
+		"presetid": "abc123",
+	})
+
+	view := viewRequest{}
+	assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+	encoded, err := json.Marshal(view)
+	assert.Nil(err, "No errors")
+	assert.Equal(`{"name":"test","presetid":"abc123","query":"test"}`, string(encoded))
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"reflect"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -43,6 +43,9 @@ func resourceAlertCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	log.Printf("[DEBUG] After %s presetalert, the created alert is %+v", req.method, createdAlert)
 
 	d.SetId(createdAlert.PresetID)
+	if err := setChannelSecretHashes(d); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return resourceAlertRead(ctx, d, m)
 }
@@ -84,19 +87,29 @@ func resourceAlertRead(ctx context.Context, d *schema.ResourceData, m interface{
 	}
 	log.Printf("[DEBUG] The GET presetalert structure is as follows: %+v\n", alert)
 
+	appendError(migrateChannelSecretHashes(d), &diags)
+	diags = append(diags, setAlertSchema(alert, d)...)
+	setMetadata(d, alert.resourceMetadata, webAppURL(pc, webPathAlert, presetID), &diags)
+
+	return diags
+}
+
+// setAlertSchema maps a preset alert from the API onto the schema. It is shared
+// by the resource and the data source so that both expose the same fields.
+func setAlertSchema(alert alertResponse, d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+
 	// Top level keys can be set directly
 	appendError(d.Set("name", alert.Name), &diags)
 
 	// Convert types to maps for setting the schema
-	integrations, diags := alert.MapChannelsToSchema()
+	integrations, channelDiags := alert.MapChannelsToSchema()
+	diags = append(diags, channelDiags...)
 	log.Printf("[DEBUG] presetalert MapChannelsToSchema result: %+v\n", integrations)
 
 	// Store the responses in the schema - note that this should also NUKE missing
 	// integrations since we have done a PUT operation. Thus, remove non-existing things.
-	for name, value := range integrations {
-		schemaKey := fmt.Sprintf("%s_channel", name)
-		appendError(d.Set(schemaKey, value), &diags)
-	}
+	setChannelsSchema(d, integrations, &diags)
 
 	return diags
 }
@@ -111,6 +124,11 @@ func resourceAlertUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 
+	diags = append(diags, mergeRemoteChannels(pc, d, fmt.Sprintf("/v1/config/presetalert/%s", presetID), &alert.Channels)...)
+	if diags.HasError() {
+		return diags
+	}
+
 	req := newRequestConfig(
 		pc,
 		"PUT",
@@ -126,6 +144,9 @@ func resourceAlertUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 	}
 
 	log.Printf("[DEBUG] %s %s SUCCESS. Remote resource updated.", req.method, req.apiURL)
+	if err := setChannelSecretHashes(d); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return resourceAlertRead(ctx, d, m)
 }
@@ -151,6 +172,19 @@ func resourceAlertDelete(ctx context.Context, d *schema.ResourceData, m interfac
 	return nil
 }
 
+// resourceAlertImportState accepts `name:<alert name>` as well as the preset ID
+func resourceAlertImportState(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if name := strings.TrimPrefix(d.Id(), importByNamePrefix); name != d.Id() {
+		alert, err := findAlertByName(m.(*providerConfig), name)
+		if err != nil {
+			return nil, err
+		}
+		d.SetId(alert.PresetID)
+	}
+	setImportDefaults(d)
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceAlert() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceAlertCreate,
@@ -158,17 +192,18 @@ func resourceAlert() *schema.Resource {
 		UpdateContext: resourceAlertUpdate,
 		DeleteContext: resourceAlertDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceAlertImportState,
 		},
 
-		Schema: map[string]*schema.Schema{
+		Schema: withMetadata(map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 			},
 			"email_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"emails": {
@@ -214,8 +249,9 @@ func resourceAlert() *schema.Resource {
 				},
 			},
 			"pagerduty_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"immediate": {
@@ -224,8 +260,9 @@ func resourceAlert() *schema.Resource {
 							Default:  "false",
 						},
 						"key": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
 						},
 						"operator": {
 							Type:     schema.TypeString,
@@ -256,8 +293,9 @@ func resourceAlert() *schema.Resource {
 				},
 			},
 			"slack_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"immediate": {
@@ -291,44 +329,43 @@ func resourceAlert() *schema.Resource {
 							},
 						},
 						"url": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
 						},
 					},
 				},
 			},
+			"raw_channel":           rawChannelSchema,
+			"channel_secret_hashes": channelSecretHashesSchema,
+			"manage_channels":       manageChannelsSchema,
+			"unknown_channels":      unknownChannelsSchema,
 			"webhook_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						"body_format": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      bodyFormatJSON,
+							ValidateFunc: validateWebhookBodyFormat,
+						},
 						"bodytemplate": {
-							Type:     schema.TypeString,
-							Optional: true,
-							// This function compares JSON, ignoring whitespace that can occur in a .tf config.
-							// Without this, `terraform apply` will think values are different from remote to state.
-							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-								var jsonOld, jsonNew interface{}
-								var err error
-								err = json.Unmarshal([]byte(old), &jsonOld)
-								if err != nil {
-									return false
-								}
-								err = json.Unmarshal([]byte(new), &jsonNew)
-								if err != nil {
-									return false
-								}
-								shouldSuppress := reflect.DeepEqual(jsonNew, jsonOld)
-								log.Println("[DEBUG] Does presetalert 'bodytemplate' value in state appear the same as remote?", shouldSuppress)
-								return shouldSuppress
-							},
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateWebhookTemplate,
+							DiffSuppressFunc: suppressWebhookBodyDiff,
 						},
 						"headers": {
 							Type: schema.TypeMap,
 							Elem: &schema.Schema{
 								Type: schema.TypeString,
 							},
-							Optional: true,
+							Optional:     true,
+							Sensitive:    true,
+							ValidateFunc: validateWebhookTemplate,
 						},
 						"immediate": {
 							Type:     schema.TypeString,
@@ -365,12 +402,14 @@ func resourceAlert() *schema.Resource {
 							},
 						},
 						"url": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							Sensitive:    true,
+							ValidateFunc: validateWebhookTemplate,
 						},
 					},
 				},
 			},
-		},
+		}),
 	}
 }
@@ -1,12 +1,14 @@
 package logdna
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 var alertDefaults = cloneDefaults(rsDefaults["alert"])
@@ -220,6 +222,7 @@ func TestAlert_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("logdna_alert.new", "pagerduty_channel.#", "0"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "slack_channel.#", "0"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.#", "0"),
+					resource.TestMatchResourceAttr("logdna_alert.new", "url", webURLPattern(`/manage/alerts/\w+$`)),
 				),
 			},
 			{
@@ -234,6 +237,12 @@ func TestAlert_Basic(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "logdna_alert.new",
+				ImportState:       true,
+				ImportStateId:     "name:test2",
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -311,8 +320,8 @@ func TestAlert_BulkChannels(t *testing.T) {
 					testAlertExists("logdna_alert.new"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "name", "test"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.#", "2"),
-					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.0.%", "9"),
-					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.1.%", "9"),
+					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.0.%", "10"),
+					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.1.%", "10"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "email_channel.#", "0"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "pagerduty_channel.#", "0"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "slack_channel.#", "0"),
@@ -364,7 +373,7 @@ func TestAlert_MultipleChannels(t *testing.T) {
 					resource.TestCheckResourceAttr("logdna_alert.new", "slack_channel.0.triggerlimit", "15"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "slack_channel.0.url", "https://hooks.slack.com/services/identifier/secret"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.#", "1"),
-					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.0.%", "9"),
+					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.0.%", "10"),
 					// The JSON will have newlines per our API which uses JSON.stringify(obj, null, 2) as the value
 					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.0.bodytemplate", "{\n  \"fields\": {\n    \"description\": \"{{ matches }} matches found for {{ name }}\",\n    \"issuetype\": {\n      \"name\": \"Bug\"\n    },\n    \"project\": {\n      \"key\": \"test\"\n    },\n    \"summary\": \"Alert from {{ name }}\"\n  }\n}"),
 					resource.TestCheckResourceAttr("logdna_alert.new", "webhook_channel.0.headers.%", "2"),
@@ -388,6 +397,37 @@ func TestAlert_MultipleChannels(t *testing.T) {
 	})
 }
 
+func TestAlert_importStateByName(t *testing.T) {
+	assert := assert.New(t)
+	ts := testAlertServer(t)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	importID := func(id string) (string, error) {
+		d := resourceAlert().Data(nil)
+		d.SetId(id)
+		imported, err := resourceAlertImportState(context.Background(), d, pc)
+		if err != nil {
+			return "", err
+		}
+		return imported[0].Id(), nil
+	}
+
+	id, err := importID("name:Checkout errors")
+	assert.Nil(err, "No errors")
+	assert.Equal("abc123", id, "The canonical ID is stored")
+
+	id, err = importID("abc123")
+	assert.Nil(err, "No errors")
+	assert.Equal("abc123", id, "IDs are passed through")
+
+	_, err = importID("name:Duplicate")
+	assert.EqualError(err, `2 preset alerts are named "Duplicate", use one of their IDs instead: [def456 ghi789]`)
+
+	_, err = importID("name:Missing")
+	assert.EqualError(err, `no preset alert is named "Missing"`)
+}
+
 func testAlertExists(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
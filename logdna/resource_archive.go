@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -11,6 +12,8 @@ import (
 
 const archiveConfigID = "archive"
 
+var archiveIntegrations = []string{"ibm", "s3", "azblob", "gcs", "dos", "swift"}
+
 type ibmConfig struct {
 	Bucket             string `json:"bucket"`
 	Endpoint           string `json:"endpoint"`
@@ -19,7 +22,14 @@ type ibmConfig struct {
 }
 
 type s3Config struct {
-	Bucket string `json:"bucket"`
+	Bucket     string `json:"bucket"`
+	RoleARN    string `json:"rolearn,omitempty"`
+	ExternalID string `json:"externalid,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Prefix     string `json:"prefix,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	SSE        string `json:"sse,omitempty"`
+	KMSKeyID   string `json:"kmskeyid,omitempty"`
 }
 
 type azblobConfig struct {
@@ -57,35 +67,53 @@ func generateArchiveConfig(d *schema.ResourceData) (interface{}, error) {
 	}
 	config := configRaw[0].(map[string]interface{})
 
-	if integration == "ibm" {
+	// Credentials are hashed in state, their configured value is sent instead
+	secrets := make(map[string]string)
+	for _, field := range archiveSecretFields[integration] {
+		secret, err := archiveSecret(d, integration, field)
+		if err != nil {
+			return nil, err
+		}
+		secrets[field] = secret
+	}
+
+	switch integration {
+	case "ibm":
 		ibm := ibmConfig{
 			Bucket:             config["bucket"].(string),
 			Endpoint:           config["endpoint"].(string),
-			APIKey:             config["apikey"].(string),
+			APIKey:             secrets["apikey"],
 			ResourceInstanceID: config["resourceinstanceid"].(string),
 		}
 		return struct {
 			Integration string `json:"integration"`
 			ibmConfig
 		}{integration, ibm}, nil
-	} else if integration == "s3" {
+	case "s3":
 		s3 := s3Config{
-			Bucket: config["bucket"].(string),
+			Bucket:     config["bucket"].(string),
+			RoleARN:    config["role_arn"].(string),
+			ExternalID: config["external_id"].(string),
+			Region:     config["region"].(string),
+			Prefix:     config["prefix"].(string),
+			Endpoint:   config["endpoint"].(string),
+			SSE:        config["sse"].(string),
+			KMSKeyID:   config["kms_key_id"].(string),
 		}
 		return struct {
 			Integration string `json:"integration"`
 			s3Config
 		}{integration, s3}, nil
-	} else if integration == "azblob" {
+	case "azblob":
 		azblob := azblobConfig{
 			AccountName: config["accountname"].(string),
-			AccountKey:  config["accountkey"].(string),
+			AccountKey:  secrets["accountkey"],
 		}
 		return struct {
 			Integration string `json:"integration"`
 			azblobConfig
 		}{integration, azblob}, nil
-	} else if integration == "gcs" {
+	case "gcs":
 		gcs := gcsConfig{
 			Bucket:    config["bucket"].(string),
 			ProjectID: config["projectid"].(string),
@@ -94,74 +122,126 @@ func generateArchiveConfig(d *schema.ResourceData) (interface{}, error) {
 			Integration string `json:"integration"`
 			gcsConfig
 		}{integration, gcs}, nil
-	} else if integration == "dos" {
+	case "dos":
 		dos := dosConfig{
 			Space:     config["space"].(string),
 			Endpoint:  config["endpoint"].(string),
-			AccessKey: config["accesskey"].(string),
-			SecretKey: config["secretkey"].(string),
+			AccessKey: secrets["accesskey"],
+			SecretKey: secrets["secretkey"],
 		}
 		return struct {
 			Integration string `json:"integration"`
 			dosConfig
 		}{integration, dos}, nil
-	} else {
+	case "swift":
 		swift := swiftConfig{
 			AuthURL:    config["authurl"].(string),
 			Expires:    config["expires"].(int),
 			Username:   config["username"].(string),
-			Password:   config["password"].(string),
+			Password:   secrets["password"],
 			TenantName: config["tenantname"].(string),
 		}
 		return struct {
 			Integration string `json:"integration"`
 			swiftConfig
 		}{integration, swift}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive integration: %s", integration)
 	}
 }
 
-func setArchiveConfig(cn archiveResponse, d *schema.ResourceData, diags diag.Diagnostics) {
+// flattenArchiveConfig maps the archive to its attributes. Every
+// `<integration>_config` block is returned, only the one of the current
+// integration is not empty. Credentials are returned as the API sent them.
+func flattenArchiveConfig(cn archiveResponse) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
 	integration := cn.Integration
-	appendError(d.Set("integration", integration), &diags)
+	attrs := map[string]interface{}{
+		"integration": integration,
+		"status":      cn.Status,
+		"last_error":  cn.LastError,
+	}
+	for _, other := range archiveIntegrations {
+		attrs[fmt.Sprintf("%s_config", other)] = []interface{}{}
+	}
 
+	var config map[string]interface{}
 	switch integration {
 	case "ibm":
-		ibmConfig := make(map[string]interface{})
-		ibmConfig["bucket"] = cn.Bucket
-		ibmConfig["endpoint"] = cn.Endpoint
-		ibmConfig["apikey"] = cn.APIKey
-		ibmConfig["resourceinstanceid"] = cn.ResourceInstanceID
-		appendError(d.Set("ibm_config", []interface{}{ibmConfig}), &diags)
+		config = map[string]interface{}{
+			"bucket":             cn.Bucket,
+			"endpoint":           cn.Endpoint,
+			"apikey":             cn.APIKey,
+			"resourceinstanceid": cn.ResourceInstanceID,
+		}
 	case "s3":
-		s3Config := make(map[string]interface{})
-		s3Config["bucket"] = cn.Bucket
-		appendError(d.Set("s3_config", []interface{}{s3Config}), &diags)
+		config = map[string]interface{}{
+			"bucket":      cn.Bucket,
+			"role_arn":    cn.RoleARN,
+			"external_id": cn.ExternalID,
+			"region":      cn.Region,
+			"prefix":      cn.Prefix,
+			"endpoint":    cn.Endpoint,
+			"sse":         cn.SSE,
+			"kms_key_id":  cn.KMSKeyID,
+		}
 	case "azblob":
-		azblobConfig := make(map[string]interface{})
-		azblobConfig["accountname"] = cn.AccountName
-		azblobConfig["accountkey"] = cn.AccountKey
-		appendError(d.Set("azblob_config", []interface{}{azblobConfig}), &diags)
+		config = map[string]interface{}{
+			"accountname": cn.AccountName,
+			"accountkey":  cn.AccountKey,
+		}
 	case "gcs":
-		gcsConfig := make(map[string]interface{})
-		gcsConfig["bucket"] = cn.Bucket
-		gcsConfig["projectid"] = cn.ProjectID
-		appendError(d.Set("gcs_config", []interface{}{gcsConfig}), &diags)
+		config = map[string]interface{}{
+			"bucket":    cn.Bucket,
+			"projectid": cn.ProjectID,
+		}
 	case "dos":
-		dosConfig := make(map[string]interface{})
-		dosConfig["space"] = cn.Space
-		dosConfig["endpoint"] = cn.Endpoint
-		dosConfig["accesskey"] = cn.AccessKey
-		dosConfig["secretkey"] = cn.SecretKey
-		appendError(d.Set("dos_config", []interface{}{dosConfig}), &diags)
+		config = map[string]interface{}{
+			"space":     cn.Space,
+			"endpoint":  cn.Endpoint,
+			"accesskey": cn.AccessKey,
+			"secretkey": cn.SecretKey,
+		}
 	case "swift":
-		swiftConfig := make(map[string]interface{})
-		swiftConfig["authurl"] = cn.AuthURL
-		swiftConfig["expires"] = cn.Expires
-		swiftConfig["username"] = cn.Username
-		swiftConfig["password"] = cn.Password
-		swiftConfig["tenantname"] = cn.TenantName
-		appendError(d.Set("swift_config", []interface{}{swiftConfig}), &diags)
+		config = map[string]interface{}{
+			"authurl":    cn.AuthURL,
+			"expires":    cn.Expires,
+			"username":   cn.Username,
+			"password":   cn.Password,
+			"tenantname": cn.TenantName,
+		}
+	default:
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unsupported archive integration",
+			Detail:   fmt.Sprintf("The archive integration %q is not supported by this provider, its settings are not read", integration),
+		})
 	}
+	if config != nil {
+		attrs[fmt.Sprintf("%s_config", integration)] = []interface{}{config}
+	}
+
+	return attrs, diags
+}
+
+func setArchiveConfig(cn archiveResponse, d *schema.ResourceData) diag.Diagnostics {
+	attrs, diags := flattenArchiveConfig(cn)
+
+	// Only the block of the current integration is kept, e.g. after a switch.
+	// Its credentials are masked by the API, state keeps their hash.
+	configKey := fmt.Sprintf("%s_config", cn.Integration)
+	if blocks, ok := attrs[configKey].([]interface{}); ok && len(blocks) == 1 {
+		config := blocks[0].(map[string]interface{})
+		for _, field := range archiveSecretFields[cn.Integration] {
+			current := d.Get(fmt.Sprintf("%s.0.%s", configKey, field)).(string)
+			config[field] = archiveSecretState(current, config[field].(string))
+		}
+	}
+
+	for key, value := range attrs {
+		appendError(d.Set(key, value), &diags)
+	}
+	return diags
 }
 
 func resourceArchiveConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -192,7 +272,18 @@ func resourceArchiveConfigCreate(ctx context.Context, d *schema.ResourceData, m
 
 	d.SetId(archiveConfigID)
 
-	return resourceArchiveConfigRead(ctx, d, m)
+	var diags diag.Diagnostics
+	setArchiveSecretHashes(d, d.Get("integration").(string), &diags)
+	if d.Get("verify").(bool) {
+		if err := waitForArchiveVerification(ctx, pc, d.Timeout(schema.TimeoutCreate)); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "The archive configuration could not be verified",
+				Detail:   err.Error(),
+			})
+		}
+	}
+	return append(diags, resourceArchiveConfigRead(ctx, d, m)...)
 }
 
 func resourceArchiveConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -228,8 +319,7 @@ func resourceArchiveConfigRead(ctx context.Context, d *schema.ResourceData, m in
 		return diags
 	}
 
-	setArchiveConfig(c, d, diags)
-	return diags
+	return append(diags, setArchiveConfig(c, d)...)
 }
 
 func resourceArchiveConfigUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -258,7 +348,18 @@ func resourceArchiveConfigUpdate(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 
-	return resourceArchiveConfigRead(ctx, d, m)
+	var diags diag.Diagnostics
+	setArchiveSecretHashes(d, d.Get("integration").(string), &diags)
+	if d.Get("verify").(bool) {
+		if err := waitForArchiveVerification(ctx, pc, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "The archive configuration could not be verified",
+				Detail:   err.Error(),
+			})
+		}
+	}
+	return append(diags, resourceArchiveConfigRead(ctx, d, m)...)
 }
 
 func resourceArchiveConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -279,32 +380,89 @@ func resourceArchiveConfigDelete(ctx context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func resourceArchiveConfig() *schema.Resource {
-	validIntegrations := []string{"ibm", "s3", "azblob", "gcs", "dos", "swift"}
+// customizeArchiveConfig requires the `<integration>_config` block matching the
+// integration, and no other block, at plan time
+func customizeArchiveConfig(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("integration") {
+		return nil
+	}
+	integration := d.Get("integration").(string)
 
+	configured := make([]string, 0)
+	for _, other := range archiveIntegrations {
+		configKey := fmt.Sprintf("%s_config", other)
+		if !d.NewValueKnown(configKey) {
+			continue
+		}
+		// The count is the only reliable value for blocks missing from the configuration
+		if _, n := d.GetChange(configKey + ".#"); n.(int) > 0 && other != integration {
+			configured = append(configured, configKey)
+		}
+	}
+	if len(configured) > 0 {
+		return fmt.Errorf("%s cannot be set when integration is %q, only %s_config can", strings.Join(configured, ", "), integration, integration)
+	}
+
+	if !containsString(archiveIntegrations, integration, false) {
+		return nil
+	}
+	if _, n := d.GetChange(fmt.Sprintf("%s_config.#", integration)); n.(int) == 0 {
+		return fmt.Errorf("integration %q requires a %s_config block", integration, integration)
+	}
+	if integration == "s3" {
+		return checkS3Config(d)
+	}
+	return nil
+}
+
+func resourceArchiveConfig() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceArchiveConfigCreate,
 		ReadContext:   resourceArchiveConfigRead,
 		UpdateContext: resourceArchiveConfigUpdate,
 		DeleteContext: resourceArchiveConfigDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+				// Only exists in Terraform
+				if err := d.Set("verify", false); err != nil {
+					return nil, err
+				}
+				return []*schema.ResourceData{d}, nil
+			},
+		},
+		CustomizeDiff: customizeArchiveConfig,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(archiveDefaultTimeout),
+			Update: schema.DefaultTimeout(archiveDefaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
+			"verify": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_error": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"integration": {
 				Type:     schema.TypeString,
 				Required: true,
 				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
 					valid := false
 					v := val.(string)
-					for _, str := range validIntegrations {
+					for _, str := range archiveIntegrations {
 						if v == str {
 							valid = true
 						}
 					}
 					if !valid {
-						errs = append(errs, fmt.Errorf("%q must be one of %v, got: %s", key, validIntegrations, v))
+						errs = append(errs, fmt.Errorf("%q must be one of %v, got: %s", key, archiveIntegrations, v))
 					}
 					return
 				},
@@ -324,8 +482,10 @@ func resourceArchiveConfig() *schema.Resource {
 							Required: true,
 						},
 						"apikey": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSecretHashDiff,
 						},
 						"resourceinstanceid": {
 							Type:     schema.TypeString,
@@ -344,6 +504,37 @@ func resourceArchiveConfig() *schema.Resource {
 							Type:     schema.TypeString,
 							Required: true,
 						},
+						"role_arn": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateIAMRoleARN,
+						},
+						"external_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"region": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"prefix": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"endpoint": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateS3Endpoint,
+						},
+						"sse": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateS3SSE,
+						},
+						"kms_key_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
 					},
 				},
 			},
@@ -358,8 +549,10 @@ func resourceArchiveConfig() *schema.Resource {
 							Required: true,
 						},
 						"accountkey": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSecretHashDiff,
 						},
 					},
 				},
@@ -396,12 +589,16 @@ func resourceArchiveConfig() *schema.Resource {
 							Required: true,
 						},
 						"accesskey": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSecretHashDiff,
 						},
 						"secretkey": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSecretHashDiff,
 						},
 					},
 				},
@@ -425,8 +622,10 @@ func resourceArchiveConfig() *schema.Resource {
 							Required: true,
 						},
 						"password": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSecretHashDiff,
 						},
 						"tenantname": {
 							Type:     schema.TypeString,
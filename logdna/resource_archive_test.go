@@ -1,13 +1,17 @@
 package logdna
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 var s3Bucket = os.Getenv("S3_BUCKET")
@@ -80,6 +84,30 @@ func TestArchiveConfig_expectMissingFieldError(t *testing.T) {
 	})
 }
 
+func TestArchiveConfig_expectMismatchedConfigError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testArchiveConfig(`
+					integration = "s3"
+					gcs_config {
+						bucket = "bucket"
+						projectid = "project"
+					}
+				`, ""),
+				ExpectError: regexp.MustCompile(`gcs_config cannot be set when integration is "s3", only s3_config can`),
+			},
+			{
+				Config: testArchiveConfig(`
+					integration = "gcs"
+				`, ""),
+				ExpectError: regexp.MustCompile(`integration "gcs" requires a gcs_config block`),
+			},
+		},
+	})
+}
+
 func TestArchiveConfig_basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers: testAccProviders,
@@ -153,3 +181,69 @@ func testArchiveConfig(fields string, url string) string {
 		}
 	`, serviceKey, uc, fields)
 }
+
+func TestArchiveConfig_customizeArchiveConfig(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceArchiveConfig()
+
+	s3 := []interface{}{map[string]interface{}{"bucket": "logs"}}
+	gcs := []interface{}{map[string]interface{}{"bucket": "logs", "projectid": "project"}}
+
+	_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"integration": "s3",
+		"s3_config":   s3,
+	}), nil)
+	assert.Nil(err, "No errors")
+
+	_, err = r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"integration": "s3",
+		"s3_config":   s3,
+		"gcs_config":  gcs,
+	}), nil)
+	assert.EqualError(err, `gcs_config cannot be set when integration is "s3", only s3_config can`)
+
+	_, err = r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"integration": "s3",
+	}), nil)
+	assert.EqualError(err, `integration "s3" requires a s3_config block`)
+
+	// Switching integrations removes the block of the previous one
+	state := &terraform.InstanceState{
+		ID: archiveConfigID,
+		Attributes: map[string]string{
+			"id":                     archiveConfigID,
+			"integration":            "gcs",
+			"gcs_config.#":           "1",
+			"gcs_config.0.bucket":    "logs",
+			"gcs_config.0.projectid": "project",
+		},
+	}
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"integration": "s3",
+		"s3_config":   s3,
+	}), nil)
+	assert.Nil(err, "No errors")
+	assert.Equal("gcs", diff.Attributes["integration"].Old)
+	assert.Equal("s3", diff.Attributes["integration"].New)
+	assert.Equal("0", diff.Attributes["gcs_config.#"].New)
+	assert.Equal("1", diff.Attributes["s3_config.#"].New)
+}
+
+func TestArchiveConfig_setArchiveConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	d := schema.TestResourceDataRaw(t, resourceArchiveConfig().Schema, map[string]interface{}{
+		"integration": "gcs",
+		"gcs_config":  []interface{}{map[string]interface{}{"bucket": "logs", "projectid": "project"}},
+	})
+	diags := setArchiveConfig(archiveResponse{Integration: "s3", Bucket: "other"}, d)
+	assert.False(diags.HasError(), "No errors")
+	assert.Equal("s3", d.Get("integration"))
+	assert.Equal("other", d.Get("s3_config.0.bucket"))
+	assert.Equal(0, d.Get("gcs_config.#"), "The previous integration is removed")
+
+	diags = setArchiveConfig(archiveResponse{Integration: "unknown"}, d)
+	assert.Len(diags, 1, "Unsupported integrations are reported")
+	assert.Equal(diag.Warning, diags[0].Severity)
+	assert.Equal(0, d.Get("s3_config.#"))
+}
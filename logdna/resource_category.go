@@ -132,6 +132,7 @@ func resourceCategoryRead(ctx context.Context, d *schema.ResourceData, m interfa
 
   appendError(d.Set("type", category.Type), &diags)
   appendError(d.Set("name", category.Name), &diags)
+  setMetadata(d, category.resourceMetadata, webAppURL(pc, webPathCategory, categoryType, categoryId), &diags)
 
   return diags
 }
@@ -172,6 +173,64 @@ func parseCategoryId(id string) (string, string, error) {
   return parts[0], parts[1], nil
 }
 
+// findCategoryIdByName returns the ID of the only category of the given type
+// named `name`
+func findCategoryIdByName(pc *providerConfig, categoryType string, name string) (string, error) {
+  categories, err := listCategories(pc, categoryType)
+
+  if err != nil {
+    return "", err
+  }
+
+  id, err := categoryIdByName(categories, categoryType, name)
+  if id == "" && err == nil {
+    err = fmt.Errorf("no %s category is named %q", categoryType, name)
+  }
+  return id, err
+}
+
+// findCategoryImportId returns `idOrName` when it is the ID of a category of
+// the given type, or else the ID of the only category named `idOrName`
+func findCategoryImportId(pc *providerConfig, categoryType string, idOrName string) (string, error) {
+  categories, err := listCategories(pc, categoryType)
+
+  if err != nil {
+    return "", err
+  }
+
+  for _, category := range categories {
+    if category.Id == idOrName {
+      return category.Id, nil
+    }
+  }
+
+  id, err := categoryIdByName(categories, categoryType, idOrName)
+  if id == "" && err == nil {
+    err = fmt.Errorf("no %s category has the ID or name %q", categoryType, idOrName)
+  }
+  return id, err
+}
+
+// categoryIdByName returns the ID of the only category named `name`, or an
+// empty ID when there is none
+func categoryIdByName(categories []categoryResponse, categoryType string, name string) (string, error) {
+  var found []string
+  for _, category := range categories {
+    if category.Name == name {
+      found = append(found, category.Id)
+    }
+  }
+
+  switch len(found) {
+  case 0:
+    return "", nil
+  case 1:
+    return found[0], nil
+  default:
+    return "", fmt.Errorf("%d %s categories are named %q, use one of their IDs instead: %v", len(found), categoryType, name, found)
+  }
+}
+
 func resourceCategory() *schema.Resource {
   return &schema.Resource{
     CreateContext: resourceCategoryCreate,
@@ -186,6 +245,19 @@ func resourceCategory() *schema.Resource {
           return nil, err
         }
 
+        // NOTE `<type>:<category name>` falls back to a lookup by name when it
+        //      is not an ID, and `<type>:name:<category name>` always looks
+        //      the category up by its name. Names may contain colons as well
+        if name := strings.TrimPrefix(categoryId, importByNamePrefix); name != categoryId {
+          categoryId, err = findCategoryIdByName(meta.(*providerConfig), categoryType, name)
+        } else {
+          categoryId, err = findCategoryImportId(meta.(*providerConfig), categoryType, categoryId)
+        }
+
+        if err != nil {
+          return nil, err
+        }
+
         if err := d.Set("type", categoryType); err != nil {
           return nil, err
         }
@@ -195,7 +267,7 @@ func resourceCategory() *schema.Resource {
         return []*schema.ResourceData{d}, nil
       },
     },
-    Schema: map[string]*schema.Schema{
+    Schema: withMetadata(map[string]*schema.Schema{
       "name": {
         Type:     schema.TypeString,
         Required: true,
@@ -207,6 +279,6 @@ func resourceCategory() *schema.Resource {
         Optional: true,
         Default:  "views",
       },
-    },
+    }),
   }
 }
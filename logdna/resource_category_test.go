@@ -8,6 +8,7 @@ import (
 
   "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
   "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+  "github.com/stretchr/testify/assert"
 )
 
 func TestCategory_ErrorProviderUrl(t *testing.T) {
@@ -81,6 +82,7 @@ func TestCategory_Basic(t *testing.T) {
         Check: resource.ComposeTestCheckFunc(
           testCategoryExists("logdna_category.new-category"),
           resource.TestCheckResourceAttr("logdna_category.new-category", "name", strings.Replace(catInsArgs["name"], "\"", "", 2)),
+          resource.TestMatchResourceAttr("logdna_category.new-category", "url", webURLPattern(`/manage/categories/views/\w+$`)),
         ),
       },
       {
@@ -96,10 +98,72 @@ func TestCategory_Basic(t *testing.T) {
         ImportState:       true,
         ImportStateVerify: true,
       },
+      {
+        // NOTE It tests an import by name
+        ResourceName:      "logdna_category.new-category",
+        ImportState:       true,
+        ImportStateId:     "views:test-category-updated",
+        ImportStateVerify: true,
+      },
+      {
+        ResourceName:      "logdna_category.new-category",
+        ImportState:       true,
+        ImportStateId:     "views:name:test-category-updated",
+        ImportStateVerify: true,
+      },
     },
   })
 }
 
+func TestCategory_findCategoryIdByName(t *testing.T) {
+  ts := testCategoryServer()
+  defer ts.Close()
+  pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+  id, err := findCategoryIdByName(pc, "views", "Checkout")
+  assert.Nil(t, err, "No errors")
+  assert.Equal(t, "abc", id, "Names are resolved to their ID")
+
+  _, err = findCategoryIdByName(pc, "views", "def")
+  assert.EqualError(t, err, `no views category is named "def"`, "IDs are not names")
+}
+
+func TestCategory_importState(t *testing.T) {
+  ts := testCategoryServer()
+  defer ts.Close()
+  pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+  importer := resourceCategory().Importer
+
+  importID := func(id string) (string, error) {
+    d := resourceCategory().Data(nil)
+    d.SetId(id)
+    results, err := importer.State(d, pc)
+    if err != nil {
+      return "", err
+    }
+    assert.Equal(t, "views", results[0].Get("type"), "The type is set")
+    return results[0].Id(), nil
+  }
+
+  id, err := importID("views:def")
+  assert.Nil(t, err, "No errors")
+  assert.Equal(t, "views:def", id, "IDs are used as-is")
+
+  id, err = importID("views:Payments")
+  assert.Nil(t, err, "No errors")
+  assert.Equal(t, "views:def", id, "Other values are looked up by name")
+
+  _, err = importID("views:unknown")
+  assert.EqualError(t, err, `no views category has the ID or name "unknown"`)
+
+  id, err = importID("views:name:Checkout")
+  assert.Nil(t, err, "No errors")
+  assert.Equal(t, "views:abc", id, "Names are resolved to their ID")
+
+  _, err = importID("views:name:Missing")
+  assert.EqualError(t, err, `no views category is named "Missing"`)
+}
+
 func testCategoryExists(n string) resource.TestCheckFunc {
   return func(s *terraform.State) error {
     rs, ok := s.RootModule().Resources[n]
@@ -41,6 +41,13 @@ func TestIngestionExclusion_expectInvalidError(t *testing.T) {
 				`, apiHostUrl),
 				ExpectError: regexp.MustCompile("requires 1 item minimum, but config has only 0 declared"),
 			},
+			{
+				Config: testIngestionExclusion(`
+					title = "test-title"
+					query = "app:api OR"
+				`, apiHostUrl),
+				ExpectError: regexp.MustCompile(`"query": invalid search query: unexpected end of query at position 11`),
+			},
 		},
 	})
 }
@@ -74,6 +81,7 @@ func TestIngestionExclusion_basic(t *testing.T) {
 					resource.TestCheckResourceAttr("logdna_ingestion_exclusion.new", "hosts.0", "host-1"),
 					resource.TestCheckResourceAttr("logdna_ingestion_exclusion.new", "hosts.1", "host-2"),
 					resource.TestCheckResourceAttr("logdna_ingestion_exclusion.new", "query", "foo bar"),
+					resource.TestMatchResourceAttr("logdna_ingestion_exclusion.new", "url", webURLPattern(`/manage/usage/exclusion-rules/\w+$`)),
 				),
 			},
 			{
@@ -11,23 +11,56 @@ import (
 const streamConfigID = "stream"
 
 type streamConfig struct {
-	Status   string   `json:"status,omitempty"`
-	Brokers  []string `json:"brokers"`
-	Topic    string   `json:"topic"`
-	User     string   `json:"user"`
-	Password string   `json:"password"`
+	Status        string   `json:"status,omitempty"`
+	LastError     string   `json:"lasterror,omitempty"`
+	Brokers       []string `json:"brokers"`
+	Topic         string   `json:"topic"`
+	User          string   `json:"user,omitempty"`
+	Password      string   `json:"password,omitempty"`
+	SASLMechanism string   `json:"saslmechanism,omitempty"`
+	TLS           *bool    `json:"tls,omitempty"`
+	TLSCACert     *string  `json:"tlscacert,omitempty"`
+	TLSClientCert *string  `json:"tlsclientcert,omitempty"`
+	TLSClientKey  *string  `json:"tlsclientkey,omitempty"`
+	Compression   string   `json:"compression,omitempty"`
+	PartitionKey  string   `json:"partitionkey,omitempty"`
+}
+
+// flattenStreamConfig maps the stream configuration to its attributes. The
+// password and the TLS client key are never returned by the API.
+func flattenStreamConfig(c streamConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"brokers":        c.Brokers,
+		"topic":          c.Topic,
+		"user":           c.User,
+		"status":         c.Status,
+		"last_error":     c.LastError,
+		"sasl_mechanism": c.SASLMechanism,
+		"compression":    c.Compression,
+		"partition_key":  c.PartitionKey,
+		"tls":            flattenStreamTLS(c),
+	}
+}
+
+func setStreamConfig(c streamConfig, d *schema.ResourceData, diags *diag.Diagnostics) {
+	attrs := flattenStreamConfig(c)
+	// The client key in state is the one that was last sent, and the defaults
+	// reported without a tls block do not add one
+	if tls := attrs["tls"].([]interface{}); d.Get("tls.#").(int) == 0 && isDefaultStreamTLS(tls) {
+		attrs["tls"] = []interface{}{}
+	} else if len(tls) > 0 {
+		tls[0].(map[string]interface{})["client_key"] = d.Get("tls.0.client_key").(string)
+	}
+	for key, value := range attrs {
+		appendError(d.Set(key, value), diags)
+	}
 }
 
 func resourceStreamConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	pc := m.(*providerConfig)
-	c := streamConfig{
-		Brokers:  listToStrings(d.Get("brokers").([]interface{})),
-		Topic:    d.Get("topic").(string),
-		User:     d.Get("user").(string),
-		Password: d.Get("password").(string),
-	}
+	c := generateStreamConfig(d)
 
 	req := newRequestConfig(
 		pc,
@@ -48,12 +81,12 @@ func resourceStreamConfigCreate(ctx context.Context, d *schema.ResourceData, m i
 	}
 
 	d.SetId(streamConfigID)
-	appendError(d.Set("brokers", cn.Brokers), &diags)
-	appendError(d.Set("topic", cn.Topic), &diags)
-	appendError(d.Set("user", cn.User), &diags)
-	appendError(d.Set("status", cn.Status), &diags)
+	setStreamConfig(cn, d, &diags)
 
-	return diags
+	if err := waitForStreamActivation(ctx, pc, d.Timeout(schema.TimeoutCreate)); err != nil {
+		diags = append(diags, streamActivationError(err))
+	}
+	return append(diags, resourceStreamConfigRead(ctx, d, m)...)
 }
 
 func resourceStreamConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -89,22 +122,14 @@ func resourceStreamConfigRead(ctx context.Context, d *schema.ResourceData, m int
 		return diags
 	}
 
-	appendError(d.Set("brokers", c.Brokers), &diags)
-	appendError(d.Set("topic", c.Topic), &diags)
-	appendError(d.Set("user", c.User), &diags)
-	appendError(d.Set("status", c.Status), &diags)
+	setStreamConfig(c, d, &diags)
 
 	return diags
 }
 
 func resourceStreamConfigUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	pc := m.(*providerConfig)
-	c := streamConfig{
-		Brokers:  listToStrings(d.Get("brokers").([]interface{})),
-		Topic:    d.Get("topic").(string),
-		User:     d.Get("user").(string),
-		Password: d.Get("password").(string),
-	}
+	c := generateStreamConfig(d)
 
 	req := newRequestConfig(
 		pc,
@@ -118,7 +143,11 @@ func resourceStreamConfigUpdate(ctx context.Context, d *schema.ResourceData, m i
 		return diag.FromErr(err)
 	}
 
-	return resourceStreamConfigRead(ctx, d, m)
+	var diags diag.Diagnostics
+	if err := waitForStreamActivation(ctx, pc, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		diags = append(diags, streamActivationError(err))
+	}
+	return append(diags, resourceStreamConfigRead(ctx, d, m)...)
 }
 
 func resourceStreamConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -148,12 +177,23 @@ func resourceStreamConfig() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(streamDefaultTimeout),
+			Update: schema.DefaultTimeout(streamDefaultTimeout),
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			return checkStreamAuth(d)
+		},
 
 		Schema: map[string]*schema.Schema{
 			"status": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"last_error": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"brokers": {
 				Type:     schema.TypeList,
 				Elem:     &schema.Schema{Type: schema.TypeString},
@@ -166,13 +206,61 @@ func resourceStreamConfig() *schema.Resource {
 			},
 			"user": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 			},
 			"password": {
 				Type:      schema.TypeString,
-				Required:  true,
+				Optional:  true,
 				Sensitive: true,
 			},
+			"sasl_mechanism": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateOneOf(streamSASLMechanisms),
+			},
+			"tls": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"ca_cert": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validatePEMCertificates,
+						},
+						"client_cert": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validatePEMCertificates,
+						},
+						"client_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							ValidateFunc: validatePEMPrivateKey,
+						},
+					},
+				},
+			},
+			"compression": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateOneOf(streamCompressions),
+			},
+			"partition_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateOneOf(streamPartitionKeys),
+			},
 		},
 	}
 }
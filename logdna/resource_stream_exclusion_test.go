@@ -41,6 +41,13 @@ func TestStreamExclusion_expectInvalidError(t *testing.T) {
 				`, ""),
 				ExpectError: regexp.MustCompile("requires 1 item minimum, but config has only 0 declared"),
 			},
+			{
+				Config: testStreamExclusion(`
+					title = "test-title"
+					query = "app:api OR"
+				`, apiHostUrl),
+				ExpectError: regexp.MustCompile(`"query": invalid search query: unexpected end of query at position 11`),
+			},
 		},
 	})
 }
@@ -74,6 +81,7 @@ func TestStreamExclusion_basic(t *testing.T) {
 					resource.TestCheckResourceAttr("logdna_stream_exclusion.new", "hosts.0", "host-1"),
 					resource.TestCheckResourceAttr("logdna_stream_exclusion.new", "hosts.1", "host-2"),
 					resource.TestCheckResourceAttr("logdna_stream_exclusion.new", "query", "query-foo AND query-bar"),
+					resource.TestMatchResourceAttr("logdna_stream_exclusion.new", "url", webURLPattern(`/manage/streaming/exclusion-rules/\w+$`)),
 				),
 			},
 			{
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"reflect"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -18,8 +17,15 @@ const (
 	PAGERDUTY = "pagerduty"
 	SLACK     = "slack"
 	WEBHOOK   = "webhook"
+	RAW       = "raw"
 )
 
+// Prefix of the import IDs that look a resource up by its name
+const importByNamePrefix = "name:"
+
+// The inline channel blocks of a view, which cannot be used along with a preset alert
+var viewChannelKeys = []string{"email_channel", "pagerduty_channel", "slack_channel", "webhook_channel", "raw_channel"}
+
 func resourceViewCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	pc := m.(*providerConfig)
@@ -29,6 +35,9 @@ func resourceViewCreate(ctx context.Context, d *schema.ResourceData, m interface
 	if diags = view.CreateRequestBody(d); diags.HasError() {
 		return diags
 	}
+	if err := resolveViewRequestCategories(pc, d, &view); err != nil {
+		return diag.FromErr(err)
+	}
 
 	req := newRequestConfig(
 		pc,
@@ -52,6 +61,9 @@ func resourceViewCreate(ctx context.Context, d *schema.ResourceData, m interface
 	log.Printf("[DEBUG] After %s view, the created view is %+v", req.method, createdView)
 
 	d.SetId(createdView.ViewID)
+	if err := setChannelSecretHashes(d); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return resourceViewRead(ctx, d, m)
 }
@@ -93,27 +105,73 @@ func resourceViewRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	}
 	log.Printf("[DEBUG] The GET view structure is as follows: %+v\n", view)
 
+	flat, flatDiags := flattenView(view)
+	diags = append(diags, flatDiags...)
+
 	// Top level keys can be set directly
-	appendError(d.Set("name", view.Name), &diags)
-	appendError(d.Set("query", view.Query), &diags)
-	appendError(d.Set("categories", view.Category), &diags)
-	appendError(d.Set("hosts", view.Hosts), &diags)
-	appendError(d.Set("tags", view.Tags), &diags)
-	appendError(d.Set("apps", view.Apps), &diags)
-	appendError(d.Set("levels", view.Levels), &diags)
+	for _, key := range []string{"name", "query", "hosts", "tags", "apps", "levels", "presetid"} {
+		appendError(d.Set(key, flat[key]), &diags)
+	}
+	if len(d.Get("category_ids").([]interface{})) > 0 {
+		categoryIds, err := viewCategoryIdsFromNames(pc, view.Category)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Cannot map the categories of the view to their IDs",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		appendError(d.Set("category_ids", categoryIds), &diags)
+	} else {
+		appendError(d.Set("categories", flat["categories"]), &diags)
+	}
+
+	// Store the channel responses in the schema - note that this should also NUKE missing
+	// integrations since we have done a PUT operation. Thus, remove non-existing things.
+	integrations := make(map[string][]interface{}, len(viewChannelKeys))
+	for _, schemaKey := range viewChannelKeys {
+		integrations[strings.TrimSuffix(schemaKey, "_channel")] = flat[schemaKey].([]interface{})
+	}
+	appendError(migrateChannelSecretHashes(d), &diags)
+	setChannelsSchema(d, integrations, &diags)
+	setMetadata(d, view.resourceMetadata, webAppURL(pc, webPathView, viewID), &diags)
+
+	return diags
+}
+
+// flattenView maps a view from the API to the values of the schema. It is
+// shared by the resource and the data sources.
+func flattenView(view viewResponse) (map[string]interface{}, diag.Diagnostics) {
+	presetID := ""
+	if len(view.PresetIDs) > 0 {
+		presetID = view.PresetIDs[0]
+	}
+
+	flat := map[string]interface{}{
+		"name":       view.Name,
+		"query":      view.Query,
+		"categories": view.Category,
+		"hosts":      view.Hosts,
+		"tags":       view.Tags,
+		"apps":       view.Apps,
+		"levels":     view.Levels,
+		"presetid":   presetID,
+	}
 
 	// Convert types to maps for setting the schema
 	integrations, diags := view.MapChannelsToSchema()
 	log.Printf("[DEBUG] view MapChannelsToSchema result: %+v\n", integrations)
 
-	// Store the channel responses in the schema - note that this should also NUKE missing
-	// integrations since we have done a PUT operation. Thus, remove non-existing things.
 	for name, value := range integrations {
-		schemaKey := fmt.Sprintf("%s_channel", name)
-		appendError(d.Set(schemaKey, value), &diags)
+		if presetID != "" {
+			// The channels belong to the preset alert, not to the view
+			value = []interface{}{}
+		}
+		flat[fmt.Sprintf("%s_channel", name)] = value
 	}
 
-	return diags
+	return flat, diags
 }
 
 func resourceViewUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -125,6 +183,16 @@ func resourceViewUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	if diags = view.CreateRequestBody(d); diags.HasError() {
 		return diags
 	}
+	if err := resolveViewRequestCategories(pc, d, &view); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if view.PresetID == "" {
+		diags = append(diags, mergeRemoteChannels(pc, d, fmt.Sprintf("/v1/config/view/%s", viewID), &view.Channels)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
 
 	req := newRequestConfig(
 		pc,
@@ -141,6 +209,9 @@ func resourceViewUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 
 	log.Printf("[DEBUG] %s %s SUCCESS. Remote resource updated.", req.method, req.apiURL)
+	if err := setChannelSecretHashes(d); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return resourceViewRead(ctx, d, m)
 }
@@ -166,6 +237,19 @@ func resourceViewDelete(ctx context.Context, d *schema.ResourceData, m interface
 	return nil
 }
 
+// resourceViewImportState accepts `name:<view name>` as well as the view ID
+func resourceViewImportState(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if name := strings.TrimPrefix(d.Id(), importByNamePrefix); name != d.Id() {
+		view, err := findViewByName(m.(*providerConfig), name)
+		if err != nil {
+			return nil, err
+		}
+		d.SetId(view.ViewID)
+	}
+	setImportDefaults(d)
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceView() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceViewCreate,
@@ -173,19 +257,22 @@ func resourceView() *schema.Resource {
 		UpdateContext: resourceViewUpdate,
 		DeleteContext: resourceViewDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceViewImportState,
 		},
+		CustomizeDiff: customizeViewCategoryIds,
 
-		Schema: map[string]*schema.Schema{
+		Schema: withMetadata(map[string]*schema.Schema{
 			"apps": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:             schema.TypeList,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNotEmpty},
+				DiffSuppressFunc: suppressStringListReorder(false),
 			},
 			"categories": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"category_ids"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					shouldSuppress := false
 					lowerCaseOld := strings.ToLower(old)
@@ -197,32 +284,58 @@ func resourceView() *schema.Resource {
 					return shouldSuppress
 				},
 			},
+			"category_ids": {
+				Type:             schema.TypeList,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString, ValidateFunc: validateViewCategoryId},
+				ConflictsWith:    []string{"categories"},
+				DiffSuppressFunc: suppressStringListReorder(false),
+			},
 			"hosts": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:             schema.TypeList,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNotEmpty},
+				DiffSuppressFunc: suppressStringListReorder(false),
 			},
 			"levels": {
 				Type:     schema.TypeList,
 				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateViewLevel,
+					StateFunc: func(val interface{}) string {
+						return canonicalViewLevel(val.(string))
+					},
+				},
+				DiffSuppressFunc: suppressViewLevelsReorder,
 			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"channel_secret_hashes": channelSecretHashesSchema,
+			"manage_channels":       manageChannelsSchema,
+			"presetid": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: viewChannelKeys,
+			},
 			"query": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateQuery,
+				DiffSuppressFunc: suppressQueryDiff,
 			},
 			"tags": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:             schema.TypeList,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNotEmpty},
+				DiffSuppressFunc: suppressStringListReorder(false),
 			},
 			"email_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"emails": {
@@ -270,8 +383,9 @@ func resourceView() *schema.Resource {
 				},
 			},
 			"pagerduty_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"immediate": {
@@ -280,8 +394,9 @@ func resourceView() *schema.Resource {
 							Default:  "false",
 						},
 						"key": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
 						},
 						"operator": {
 							Type:     schema.TypeString,
@@ -312,8 +427,9 @@ func resourceView() *schema.Resource {
 				},
 			},
 			"slack_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"immediate": {
@@ -347,44 +463,41 @@ func resourceView() *schema.Resource {
 							},
 						},
 						"url": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
 						},
 					},
 				},
 			},
+			"raw_channel":      rawChannelSchema,
+			"unknown_channels": unknownChannelsSchema,
 			"webhook_channel": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressChannelReorder,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						"body_format": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      bodyFormatJSON,
+							ValidateFunc: validateWebhookBodyFormat,
+						},
 						"bodytemplate": {
-							Type:     schema.TypeString,
-							Optional: true,
-							// This function compares JSON, ignoring whitespace that can occur in a .tf config.
-							// Without this, `terraform apply` will think values are different from remote to state.
-							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-								var jsonOld, jsonNew interface{}
-								var err error
-								err = json.Unmarshal([]byte(old), &jsonOld)
-								if err != nil {
-									return false
-								}
-								err = json.Unmarshal([]byte(new), &jsonNew)
-								if err != nil {
-									return false
-								}
-								shouldSuppress := reflect.DeepEqual(jsonNew, jsonOld)
-								log.Println("[DEBUG] Does view 'bodytemplate' value in state appear the same as remote?", shouldSuppress)
-								return shouldSuppress
-							},
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateWebhookTemplate,
+							DiffSuppressFunc: suppressWebhookBodyDiff,
 						},
 						"headers": {
 							Type: schema.TypeMap,
 							Elem: &schema.Schema{
 								Type: schema.TypeString,
 							},
-							Optional: true,
+							Optional:     true,
+							Sensitive:    true,
+							ValidateFunc: validateWebhookTemplate,
 						},
 						"immediate": {
 							Type:     schema.TypeString,
@@ -421,12 +534,14 @@ func resourceView() *schema.Resource {
 							},
 						},
 						"url": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							Sensitive:    true,
+							ValidateFunc: validateWebhookTemplate,
 						},
 					},
 				},
 			},
-		},
+		}),
 	}
 }
@@ -0,0 +1,580 @@
+package logdna
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// A view set manages many views from a single resource, e.g. views generated
+// from a catalog of services. Every view is identified by a `key` that is
+// stable across renames, and `view_ids` maps each key to the ID of its view.
+// Only the views whose definition changed are sent to the API, in parallel.
+
+const (
+	viewSetCreate = "create"
+	viewSetUpdate = "update"
+	viewSetDelete = "delete"
+)
+
+// The channel blocks supported in a view set
+var viewSetChannels = []string{EMAIL, PAGERDUTY, SLACK, WEBHOOK}
+
+type viewSetChange struct {
+	action     string
+	key        string
+	id         string
+	definition map[string]interface{}
+}
+
+type viewSetResult struct {
+	id  string
+	err error
+}
+
+func resourceViewSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId(newViewSetID())
+
+	// An error would taint the set, and the next apply would replace every view.
+	// The views that failed are left out of state and planned again, so they
+	// are only warnings, unless no view could be created at all.
+	diags := resourceViewSetApply(ctx, d, m, diag.Warning)
+	if len(viewSetIds(d)) == 0 && len(diags) > 0 {
+		d.SetId("")
+		for i := range diags {
+			diags[i].Severity = diag.Error
+		}
+	}
+	return diags
+}
+
+func resourceViewSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceViewSetApply(ctx, d, m, diag.Error)
+}
+
+// resourceViewSetApply applies the difference between the views in state and
+// in the configuration. The state only records the changes that succeeded, so
+// that the failed ones are planned again. Each failed change is reported with
+// the given severity.
+func resourceViewSetApply(ctx context.Context, d *schema.ResourceData, m interface{}, severity diag.Severity) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pc := m.(*providerConfig)
+
+	o, n := d.GetChange("view")
+	oldViews := viewSetByKey(o.(*schema.Set).List())
+	newViews := viewSetByKey(n.(*schema.Set).List())
+	ids := viewSetIds(d)
+
+	changes := planViewSetChanges(oldViews, newViews, ids)
+	log.Printf("[DEBUG] view set %s has %d changes", d.Id(), len(changes))
+	results := applyViewSetChanges(pc, changes, d.Get("max_concurrency").(int))
+
+	views := make(map[string]map[string]interface{}, len(newViews))
+	for key, view := range oldViews {
+		if _, ok := ids[key]; ok {
+			views[key] = view
+		}
+	}
+	for _, change := range changes {
+		result := results[change.key]
+		if result.err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: severity,
+				Summary:  fmt.Sprintf("Cannot %s view %q of the view set", change.action, change.key),
+				Detail:   result.err.Error(),
+			})
+			continue
+		}
+
+		switch change.action {
+		case viewSetDelete:
+			delete(views, change.key)
+			delete(ids, change.key)
+		default:
+			views[change.key] = change.definition
+			ids[change.key] = result.id
+		}
+	}
+	// Views that did not change still take the configuration, e.g. for whitespace
+	for key, view := range newViews {
+		if _, ok := views[key]; ok && results[key].err == nil {
+			views[key] = view
+		}
+	}
+
+	setViewSetState(d, views, ids, &diags)
+	// Every view left in state was sent to the API as it is, or was already
+	appendError(d.Set("channel_secret_hashes", viewSetSecretHashes(getChannelSecretHashes(d), views)), &diags)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, resourceViewSetRead(ctx, d, m)...)
+}
+
+func resourceViewSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pc := m.(*providerConfig)
+
+	// A single request for the whole set, rather than one per view
+	remoteViews, err := listViews(pc)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot list the remote view resources",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	byID := make(map[string]viewResponse, len(remoteViews))
+	for _, view := range remoteViews {
+		byID[view.ViewID] = view
+	}
+
+	current := viewSetByKey(d.Get("view").(*schema.Set).List())
+	hashes := getChannelSecretHashes(d)
+	if len(hashes) == 0 {
+		// States written before the secrets were hashed hold the secrets that were last sent
+		hashes = viewSetSecretHashes(nil, current)
+		appendError(d.Set("channel_secret_hashes", hashes), &diags)
+	}
+	ids := viewSetIds(d)
+	views := make(map[string]map[string]interface{}, len(ids))
+	for key, id := range ids {
+		remote, ok := byID[id]
+		if !ok {
+			log.Printf("[WARN] view %s of the view set (%s) was not found, removing it from state", key, id)
+			delete(ids, key)
+			continue
+		}
+
+		flat, flatDiags := flattenView(remote)
+		diags = append(diags, flatDiags...)
+		if raw := flat["raw_channel"].([]interface{}); len(raw) > 0 {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("View %q of the view set has channels that a view set cannot manage", key),
+				Detail:   "The view has channels of integrations that are only supported by logdna_view. It cannot be updated from the view set, which would delete them.",
+			})
+		}
+		views[key] = viewSetDefinition(key, flat, current[key], hashes)
+	}
+
+	setViewSetState(d, views, ids, &diags)
+	return diags
+}
+
+func resourceViewSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pc := m.(*providerConfig)
+
+	views := viewSetByKey(d.Get("view").(*schema.Set).List())
+	ids := viewSetIds(d)
+
+	changes := make([]viewSetChange, 0, len(ids))
+	for key, id := range ids {
+		changes = append(changes, viewSetChange{action: viewSetDelete, key: key, id: id})
+	}
+	results := applyViewSetChanges(pc, changes, d.Get("max_concurrency").(int))
+
+	for _, change := range changes {
+		if err := results[change.key].err; err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Cannot delete view %q of the view set", change.key),
+				Detail:   err.Error(),
+			})
+			continue
+		}
+		delete(views, change.key)
+		delete(ids, change.key)
+	}
+
+	if diags.HasError() {
+		// Keep the views that could not be deleted
+		setViewSetState(d, views, ids, &diags)
+		return diags
+	}
+	d.SetId("")
+	return nil
+}
+
+// planViewSetChanges returns the minimal changes to go from the old views to
+// the new ones. Views whose definition only differs in formatting are left out.
+func planViewSetChanges(
+	oldViews map[string]map[string]interface{},
+	newViews map[string]map[string]interface{},
+	ids map[string]string,
+) []viewSetChange {
+	changes := make([]viewSetChange, 0)
+
+	for _, key := range sortedKeys(newViews) {
+		view := newViews[key]
+		id, exists := ids[key]
+		switch {
+		case !exists:
+			changes = append(changes, viewSetChange{action: viewSetCreate, key: key, definition: view})
+		case !viewDefinitionsEqual(oldViews[key], view):
+			changes = append(changes, viewSetChange{action: viewSetUpdate, key: key, id: id, definition: view})
+		}
+	}
+	for _, key := range sortedKeys(oldViews) {
+		if _, ok := newViews[key]; ok {
+			continue
+		}
+		if id, exists := ids[key]; exists {
+			changes = append(changes, viewSetChange{action: viewSetDelete, key: key, id: id})
+		}
+	}
+	return changes
+}
+
+// applyViewSetChanges sends the changes to the API with at most `concurrency`
+// requests in flight. An error on one view does not stop the others.
+func applyViewSetChanges(pc *providerConfig, changes []viewSetChange, concurrency int) map[string]viewSetResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make(map[string]viewSetResult, len(changes))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, change := range changes {
+		wg.Add(1)
+		go func(change viewSetChange) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			id, err := applyViewSetChange(pc, change)
+			mutex.Lock()
+			results[change.key] = viewSetResult{id: id, err: err}
+			mutex.Unlock()
+		}(change)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func applyViewSetChange(pc *providerConfig, change viewSetChange) (string, error) {
+	var req *requestConfig
+
+	switch change.action {
+	case viewSetDelete:
+		req = newRequestConfig(pc, "DELETE", fmt.Sprintf("/v1/config/view/%s", change.id), nil)
+	default:
+		view, diags := viewRequestFromDefinition(change.definition)
+		if diags.HasError() {
+			return "", fmt.Errorf("%s: %s", diags[0].Summary, diags[0].Detail)
+		}
+		if change.action == viewSetCreate {
+			req = newRequestConfig(pc, "POST", "/v1/config/view", view)
+		} else {
+			if err := checkViewSetChannels(pc, change.id); err != nil {
+				return "", err
+			}
+			req = newRequestConfig(pc, "PUT", fmt.Sprintf("/v1/config/view/%s", change.id), view)
+		}
+	}
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] %s %s view set %s, payload is: %s", req.method, req.apiURL, change.key, body)
+	if err != nil {
+		return "", err
+	}
+	if change.action != viewSetCreate {
+		return change.id, nil
+	}
+
+	created := viewResponse{}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", err
+	}
+	return created.ViewID, nil
+}
+
+// checkViewSetChannels refuses to update a view with channels that a view set
+// does not model, since the update would delete them
+func checkViewSetChannels(pc *providerConfig, id string) error {
+	remote, err := getRemoteChannels(pc, fmt.Sprintf("/v1/config/view/%s", id))
+	if err != nil {
+		return err
+	}
+	for _, c := range remote {
+		if !isModeledIntegration(c.Integration) {
+			return fmt.Errorf("the view has a channel of the %s integration, which a view set cannot manage and an update would delete. Use logdna_view to manage this view", c.Integration)
+		}
+	}
+	return nil
+}
+
+// viewRequestFromDefinition builds the request for a view of the set, the same
+// way as viewRequest.CreateRequestBody does for a `logdna_view`
+func viewRequestFromDefinition(view map[string]interface{}) (viewRequest, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	req := viewRequest{
+		Name:     view["name"].(string),
+		Query:    view["query"].(string),
+		PresetID: view["presetid"].(string),
+		Apps:     listToStrings(view["apps"].([]interface{})),
+		Category: listToStrings(view["categories"].([]interface{})),
+		Hosts:    listToStrings(view["hosts"].([]interface{})),
+		Levels:   canonicalViewLevels(listToStrings(view["levels"].([]interface{}))),
+		Tags:     listToStrings(view["tags"].([]interface{})),
+	}
+
+	if req.PresetID == "" {
+		for _, integration := range viewSetChannels {
+			channels := view[fmt.Sprintf("%s_channel", integration)].([]interface{})
+			req.Channels = append(req.Channels, *iterateIntegrationType(channels, integration, &diags)...)
+		}
+	}
+	return req, diags
+}
+
+// viewSetDefinition returns the definition to store in state for a remote view.
+// The current definition is kept when it is equivalent, so that formatting
+// differences are not reported as drift.
+func viewSetDefinition(key string, flat map[string]interface{}, current map[string]interface{}, hashes []string) map[string]interface{} {
+	view := map[string]interface{}{"key": key}
+	for k := range viewSetElem {
+		if v, ok := flat[k]; ok {
+			view[k] = v
+		}
+	}
+	for _, k := range []string{"apps", "categories", "hosts", "levels", "tags"} {
+		view[k] = stringsToList(view[k])
+	}
+	for _, integration := range viewSetChannels {
+		schemaKey := fmt.Sprintf("%s_channel", integration)
+		view[schemaKey] = stringsToList(view[schemaKey])
+	}
+
+	if current == nil {
+		return view
+	}
+	for _, integration := range viewSetChannels {
+		schemaKey := fmt.Sprintf("%s_channel", integration)
+		view[schemaKey] = unmaskChannels(integration, current[schemaKey].([]interface{}), view[schemaKey].([]interface{}), hashes)
+		if integration == WEBHOOK {
+			view[schemaKey] = keepWebhookBodyFormats(current[schemaKey].([]interface{}), view[schemaKey].([]interface{}))
+		}
+	}
+	if viewDefinitionsEqual(current, view) {
+		return current
+	}
+	return view
+}
+
+// viewDefinitionsEqual compares two views of the set the same way the
+// DiffSuppressFuncs of `logdna_view` do
+func viewDefinitionsEqual(a, b map[string]interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return viewSetFingerprint(a) == viewSetFingerprint(b)
+}
+
+// viewSetFingerprint returns the canonical form of a view of the set, which
+// is the same for views that only differ in formatting. The key is left out.
+func viewSetFingerprint(view map[string]interface{}) string {
+	name, _ := view["name"].(string)
+	presetID, _ := view["presetid"].(string)
+	query, _ := view["query"].(string)
+	if normalized, err := normalizeQuery(query); err == nil {
+		query = normalized
+	}
+	levels, _ := view["levels"].([]interface{})
+
+	parts := []string{name, presetID, query, strings.Join(sortedViewLevels(levels), ",")}
+	for _, k := range []string{"apps", "categories", "hosts", "tags"} {
+		list, _ := view[k].([]interface{})
+		parts = append(parts, strings.Join(sortedStrings(list, k == "categories"), ","))
+	}
+	for _, integration := range viewSetChannels {
+		channels, _ := view[fmt.Sprintf("%s_channel", integration)].([]interface{})
+		fingerprints := make([]string, 0, len(channels))
+		for _, c := range channels {
+			if cm, ok := c.(map[string]interface{}); ok {
+				fingerprints = append(fingerprints, channelFingerprint(integration, cm))
+			}
+		}
+		sort.Strings(fingerprints)
+		parts = append(parts, strings.Join(fingerprints, ","))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// hashViewSet is the hash of the views in the set. Views are hashed by key and
+// canonical definition, so that reordering them, or formatting differences,
+// is not a diff, and the plan only shows the views that changed.
+func hashViewSet(v interface{}) int {
+	view := v.(map[string]interface{})
+	key, _ := view["key"].(string)
+	return schema.HashString(key + "\n" + viewSetFingerprint(view))
+}
+
+// setViewSetState stores the views and their IDs in state
+func setViewSetState(d *schema.ResourceData, views map[string]map[string]interface{}, ids map[string]string, diags *diag.Diagnostics) {
+	list := make([]interface{}, 0, len(views))
+	for _, key := range sortedKeys(views) {
+		list = append(list, views[key])
+	}
+	appendError(d.Set("view", list), diags)
+	appendError(d.Set("view_ids", ids), diags)
+}
+
+// viewSetSecretHashes returns the hashes of the channel secrets of every view
+func viewSetSecretHashes(current []string, views map[string]map[string]interface{}) []string {
+	integrations := make(map[string][]interface{}, len(channelSecretFields))
+	for _, view := range views {
+		for integration := range channelSecretFields {
+			schemaKey := fmt.Sprintf("%s_channel", integration)
+			channels, _ := view[schemaKey].([]interface{})
+			integrations[integration] = append(integrations[integration], channels...)
+		}
+	}
+	return channelSecretHashes(current, integrations)
+}
+
+func viewSetByKey(list []interface{}) map[string]map[string]interface{} {
+	views := make(map[string]map[string]interface{}, len(list))
+	for _, elem := range list {
+		view := elem.(map[string]interface{})
+		views[view["key"].(string)] = view
+	}
+	return views
+}
+
+func viewSetIds(d *schema.ResourceData) map[string]string {
+	ids := make(map[string]string)
+	for key, id := range d.Get("view_ids").(map[string]interface{}) {
+		ids[key] = id.(string)
+	}
+	return ids
+}
+
+func sortedKeys(views map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(views))
+	for key := range views {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringsToList(v interface{}) []interface{} {
+	list := make([]interface{}, 0)
+	switch values := v.(type) {
+	case []string:
+		for _, s := range values {
+			list = append(list, s)
+		}
+	case []interface{}:
+		list = append(list, values...)
+	}
+	return list
+}
+
+func newViewSetID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("[WARN] Cannot generate a random view set ID: %s", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// customizeViewSetKeys rejects views that share the same key
+func customizeViewSetKeys(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	seen := make(map[string]bool)
+	for _, elem := range d.Get("view").(*schema.Set).List() {
+		key := elem.(map[string]interface{})["key"].(string)
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			return fmt.Errorf("more than one view of the set has the key %q", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// viewSetElem is the schema of a view in the set, built once
+var viewSetElem = viewSetElemSchema()
+
+// viewSetElemSchema returns the schema of a view in the set. Channel blocks reuse
+// the blocks of `logdna_view`, without the list-level DiffSuppressFunc which
+// does not apply inside of a set.
+func viewSetElemSchema() map[string]*schema.Schema {
+	view := resourceView().Schema
+	elem := map[string]*schema.Schema{
+		"key": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+	}
+	for _, k := range []string{"name", "query", "apps", "categories", "hosts", "levels", "tags", "presetid"} {
+		s := *view[k]
+		s.DiffSuppressFunc = nil
+		s.ConflictsWith = nil
+		elem[k] = &s
+	}
+	for _, integration := range viewSetChannels {
+		schemaKey := fmt.Sprintf("%s_channel", integration)
+		s := *view[schemaKey]
+		s.DiffSuppressFunc = nil
+		elem[schemaKey] = &s
+	}
+	return elem
+}
+
+func resourceViewSet() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceViewSetCreate,
+		ReadContext:   resourceViewSetRead,
+		UpdateContext: resourceViewSetUpdate,
+		DeleteContext: resourceViewSetDelete,
+		CustomizeDiff: customizeViewSetKeys,
+
+		Schema: map[string]*schema.Schema{
+			"view": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Set:      hashViewSet,
+				Elem: &schema.Resource{
+					Schema: viewSetElem,
+				},
+			},
+			"channel_secret_hashes": channelSecretHashesSchema,
+			"max_concurrency": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  8,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if val.(int) < 1 {
+						errs = append(errs, fmt.Errorf("%q must be at least 1, got: %d", key, val))
+					}
+					return
+				},
+			},
+			"view_ids": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
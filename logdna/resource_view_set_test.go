@@ -0,0 +1,375 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// viewSetServer is an in-memory view API. Views named "broken" are rejected,
+// and it records the highest number of requests handled at the same time.
+type viewSetServer struct {
+	*httptest.Server
+	mutex       sync.Mutex
+	views       map[string]map[string]interface{}
+	requests    []string
+	inFlight    int
+	maxInFlight int
+	nextID      int
+}
+
+func newViewSetServer() *viewSetServer {
+	s := &viewSetServer{views: make(map[string]map[string]interface{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *viewSetServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxInFlight {
+		s.maxInFlight = s.inFlight
+	}
+	if r.Method != "GET" {
+		s.requests = append(s.requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+	}
+	s.mutex.Unlock()
+
+	// Let concurrent requests overlap
+	time.Sleep(10 * time.Millisecond)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer func() { s.inFlight-- }()
+
+	id := strings.TrimPrefix(r.URL.Path, "/v1/config/view/")
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v1/config/view":
+		views := make([]map[string]interface{}, 0, len(s.views))
+		for _, view := range s.views {
+			views = append(views, view)
+		}
+		_ = json.NewEncoder(w).Encode(views)
+	case r.Method == "GET" && s.views[id] != nil:
+		_ = json.NewEncoder(w).Encode(s.views[id])
+	case r.Method == "POST" || r.Method == "PUT":
+		view := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&view)
+		if view["name"] == "broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "broken is not a valid name"}`)
+			return
+		}
+		if r.Method == "POST" {
+			s.nextID++
+			id = fmt.Sprintf("view%d", s.nextID)
+		} else if _, ok := s.views[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		view["viewID"] = id
+		s.views[id] = view
+		_ = json.NewEncoder(w).Encode(view)
+	case r.Method == "DELETE":
+		delete(s.views, id)
+		fmt.Fprint(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testViewSetDefinition(key, name string) map[string]interface{} {
+	view := map[string]interface{}{
+		"key":        key,
+		"name":       name,
+		"query":      "",
+		"presetid":   "",
+		"apps":       []interface{}{},
+		"categories": []interface{}{},
+		"hosts":      []interface{}{},
+		"levels":     []interface{}{},
+		"tags":       []interface{}{},
+	}
+	for _, integration := range viewSetChannels {
+		view[fmt.Sprintf("%s_channel", integration)] = []interface{}{}
+	}
+	return view
+}
+
+func TestViewSet_planViewSetChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	unchanged := testViewSetDefinition("unchanged", "Unchanged")
+	unchanged["query"] = "app:api  level:error"
+	unchanged["levels"] = []interface{}{"error", "WARN"}
+	reformatted := testViewSetDefinition("unchanged", "Unchanged")
+	reformatted["query"] = "app:api level:error"
+	reformatted["levels"] = []interface{}{"warn", "error"}
+
+	oldViews := map[string]map[string]interface{}{
+		"unchanged": unchanged,
+		"renamed":   testViewSetDefinition("renamed", "Before"),
+		"removed":   testViewSetDefinition("removed", "Removed"),
+	}
+	newViews := map[string]map[string]interface{}{
+		"unchanged": reformatted,
+		"renamed":   testViewSetDefinition("renamed", "After"),
+		"added":     testViewSetDefinition("added", "Added"),
+	}
+	ids := map[string]string{"unchanged": "a", "renamed": "b", "removed": "c"}
+
+	changes := planViewSetChanges(oldViews, newViews, ids)
+	assert.Len(changes, 3, "Equivalent views are not changed")
+	assert.Equal(viewSetChange{action: viewSetCreate, key: "added", definition: newViews["added"]}, changes[0])
+	assert.Equal(viewSetChange{action: viewSetUpdate, key: "renamed", id: "b", definition: newViews["renamed"]}, changes[1])
+	assert.Equal(viewSetChange{action: viewSetDelete, key: "removed", id: "c"}, changes[2])
+}
+
+func TestViewSet_applyViewSetChanges(t *testing.T) {
+	assert := assert.New(t)
+	s := newViewSetServer()
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+
+	changes := make([]viewSetChange, 0)
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("view%d", i)
+		changes = append(changes, viewSetChange{action: viewSetCreate, key: key, definition: testViewSetDefinition(key, key)})
+	}
+	changes = append(changes, viewSetChange{action: viewSetCreate, key: "broken", definition: testViewSetDefinition("broken", "broken")})
+
+	results := applyViewSetChanges(pc, changes, 3)
+	assert.Len(results, 11, "Every change has a result")
+	assert.LessOrEqual(s.maxInFlight, 3, "Concurrency is bounded")
+	assert.Greater(s.maxInFlight, 1, "Changes are applied concurrently")
+	assert.Len(s.views, 10, "Errors do not abort the other changes")
+	assert.NotNil(results["broken"].err)
+	assert.Contains(results["broken"].err.Error(), "broken is not a valid name")
+	for i := 0; i < 10; i++ {
+		assert.Nil(results[fmt.Sprintf("view%d", i)].err)
+		assert.NotEmpty(results[fmt.Sprintf("view%d", i)].id)
+	}
+}
+
+func TestViewSet_Apply(t *testing.T) {
+	assert := assert.New(t)
+	s := newViewSetServer()
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+	r := resourceViewSet()
+
+	api := map[string]interface{}{"key": "api", "name": "API errors", "query": "level:error", "apps": []interface{}{"api"}}
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"view": []interface{}{api, map[string]interface{}{"key": "web", "name": "broken"}},
+	})
+	diags := resourceViewSetCreate(context.Background(), d, pc)
+	assert.False(diags.HasError(), "A failed view does not fail, and taint, the whole set")
+	assert.Len(diags, 1, "Only the failed view is reported")
+	assert.Equal(diag.Warning, diags[0].Severity)
+	assert.Equal(`Cannot create view "web" of the view set`, diags[0].Summary)
+	assert.NotEmpty(d.Id(), "The set is created")
+	assert.Equal(map[string]interface{}{"api": "view1"}, d.Get("view_ids"), "Only created views are in state")
+	assert.Equal(1, d.Get("view.#"))
+
+	plan := func(views ...interface{}) *schema.ResourceData {
+		state := d.State()
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{"view": views}), pc)
+		assert.Nil(err, "No errors")
+		if diff == nil {
+			return nil
+		}
+		assert.False(diff.RequiresNew(), "The set is not replaced")
+		planned, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(err, "No errors")
+		return planned
+	}
+
+	// Fix the failed view: the view that was created is left as it is
+	d = plan(api, map[string]interface{}{"key": "web", "name": "Web errors"})
+	s.requests = nil
+	diags = resourceViewSetUpdate(context.Background(), d, pc)
+	assert.False(diags.HasError(), "No errors")
+	assert.Equal([]string{"POST /v1/config/view"}, s.requests, "Only the failed view is created")
+	assert.Equal(map[string]interface{}{"api": "view1", "web": "view2"}, d.Get("view_ids"))
+
+	// Reordering the views is not a diff
+	web := map[string]interface{}{"key": "web", "name": "Web errors"}
+	assert.Nil(plan(web, api), "Reordered views are not a diff")
+
+	// Formatting differences are not a diff
+	reformatted := map[string]interface{}{"key": "api", "name": "API errors", "query": "level:error ", "apps": []interface{}{"api"}}
+	assert.Nil(plan(web, reformatted), "Formatting differences are not a diff")
+
+	// Change the query of one view only
+	changed := map[string]interface{}{"key": "api", "name": "API errors", "query": "level:error level:fatal", "apps": []interface{}{"api"}}
+	d = plan(changed, web)
+	s.requests = nil
+	diags = resourceViewSetUpdate(context.Background(), d, pc)
+	assert.False(diags.HasError(), "No errors")
+	assert.Equal([]string{"PUT /v1/config/view/view1"}, s.requests, "Only the changed view is updated")
+
+	// Views deleted outside of Terraform are removed from state
+	delete(s.views, "view2")
+	assert.False(resourceViewSetRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(map[string]interface{}{"api": "view1"}, d.Get("view_ids"))
+	assert.Equal(1, d.Get("view.#"))
+
+	s.requests = nil
+	assert.False(resourceViewSetDelete(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal([]string{"DELETE /v1/config/view/view1"}, s.requests)
+	assert.Empty(s.views)
+}
+
+func TestViewSet_planOnlyChangedViews(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceViewSet()
+
+	views := make([]interface{}, 0)
+	ids := make(map[string]interface{})
+	for _, key := range []string{"api", "web", "worker"} {
+		views = append(views, map[string]interface{}{"key": key, "name": key})
+		ids[key] = "id-" + key
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"view": views})
+	d.SetId("set")
+	assert.Nil(d.Set("view_ids", ids))
+	state := d.State()
+
+	added := map[string]interface{}{"key": "batch", "name": "batch"}
+	changed := map[string]interface{}{"key": "web", "name": "Web"}
+	config := []interface{}{views[0], changed, added, views[2]}
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{"view": config}), nil)
+	assert.Nil(err, "No errors")
+
+	// Unchanged views are in the diff of a set too, with the same old and new values
+	codes := map[string]bool{}
+	for k, attr := range diff.Attributes {
+		if attr.Old == attr.New {
+			continue
+		}
+		if parts := strings.SplitN(k, ".", 3); len(parts) == 3 && parts[0] == "view" {
+			codes[parts[1]] = true
+		}
+	}
+	assert.Equal(map[string]bool{
+		strconv.Itoa(hashViewSet(added)):    true,
+		strconv.Itoa(hashViewSet(views[1])): true,
+		strconv.Itoa(hashViewSet(changed)):  true,
+	}, codes, "Only the added and changed views are in the diff")
+	assert.True(diff.Attributes[fmt.Sprintf("view.%d.name", hashViewSet(views[1]))].NewRemoved, "The old definition is removed")
+	assert.Equal("Web", diff.Attributes[fmt.Sprintf("view.%d.name", hashViewSet(changed))].New)
+	assert.Equal("4", diff.Attributes["view.#"].New)
+}
+
+func TestViewSet_CreateFails(t *testing.T) {
+	assert := assert.New(t)
+	s := newViewSetServer()
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+
+	d := schema.TestResourceDataRaw(t, resourceViewSet().Schema, map[string]interface{}{
+		"view": []interface{}{map[string]interface{}{"key": "web", "name": "broken"}},
+	})
+	diags := resourceViewSetCreate(context.Background(), d, pc)
+	assert.True(diags.HasError(), "Nothing was created")
+	assert.Empty(d.Id(), "There is nothing to keep in state")
+}
+
+func TestViewSet_unmodeledChannels(t *testing.T) {
+	assert := assert.New(t)
+	s := newViewSetServer()
+	defer s.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: s.URL}
+	s.views["view1"] = map[string]interface{}{
+		"viewID":   "view1",
+		"name":     "API errors",
+		"channels": []interface{}{map[string]interface{}{"integration": "opsgenie", "operator": "presence"}},
+	}
+
+	_, err := applyViewSetChange(pc, viewSetChange{
+		action:     viewSetUpdate,
+		key:        "api",
+		id:         "view1",
+		definition: testViewSetDefinition("api", "API errors"),
+	})
+	assert.NotNil(err, "The view is not updated")
+	assert.Contains(err.Error(), "the view has a channel of the opsgenie integration, which a view set cannot manage")
+	assert.Empty(s.requests, "No request was sent")
+}
+
+func TestViewSet_customizeViewSetKeys(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceViewSet()
+
+	_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"view": []interface{}{
+			map[string]interface{}{"key": "api", "name": "API"},
+			map[string]interface{}{"key": "api", "name": "Another API"},
+		},
+	}), nil)
+	assert.NotNil(err, "Duplicate keys are an error")
+	assert.Contains(err.Error(), `more than one view of the set has the key "api"`)
+}
+
+func TestViewSet_Basic(t *testing.T) {
+	pc := fmtProviderBlock()
+	rs := `
+resource "logdna_view_set" "services" {
+	max_concurrency = 2
+
+	view {
+		key   = "api"
+		name  = "API errors"
+		query = "level:error"
+		apps  = ["api"]
+	}
+
+	view {
+		key    = "web"
+		name   = "Web warnings"
+		levels = ["warn"]
+		email_channel {
+			emails          = ["test@logdna.com"]
+			operator        = "presence"
+			triggerinterval = "15m"
+			triggerlimit    = 15
+		}
+	}
+}`
+	renamed := strings.Replace(rs, "Web warnings", "Web warnings and errors", 1)
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: pc + rs,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("logdna_view_set.services", "view.#", "2"),
+					resource.TestCheckResourceAttr("logdna_view_set.services", "view_ids.%", "2"),
+					resource.TestCheckResourceAttrSet("logdna_view_set.services", "view_ids.api"),
+					resource.TestCheckResourceAttrSet("logdna_view_set.services", "view_ids.web"),
+				),
+			},
+			{
+				Config: pc + renamed,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("logdna_view_set.services", "view.#", "2"),
+					resource.TestCheckResourceAttr("logdna_view_set.services", "view_ids.%", "2"),
+				),
+			},
+		},
+	})
+}
@@ -1,12 +1,14 @@
 package logdna
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 const ctgies = `["DEMOCATEGORY1", "DemoCategory2"]`
@@ -52,6 +54,10 @@ func TestView_ErrorsResourceFields(t *testing.T) {
 	tgs["tags"] = `"invalid tags value"`
 	tgsCfg := fmtTestConfigResource("view", "new", nilLst, tgs, nilOpt, nilLst)
 
+	qry := cloneDefaults(rsDefaults["view"])
+	qry["query"] = `"level:error (app:api"`
+	qryCfg := fmtTestConfigResource("view", "new", nilLst, qry, nilOpt, nilLst)
+
 	resource.Test(t, resource.TestCase{
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -79,6 +85,10 @@ func TestView_ErrorsResourceFields(t *testing.T) {
 				Config:      tgsCfg,
 				ExpectError: regexp.MustCompile("Inappropriate value for attribute \"tags\": list of string required."),
 			},
+			{
+				Config:      qryCfg,
+				ExpectError: regexp.MustCompile(`"query": invalid search query: missing "\)" to close "\(" at position 13`),
+			},
 		},
 	})
 }
@@ -199,6 +209,10 @@ func TestView_ErrorsWebhookChannel(t *testing.T) {
 	btArgs["webhook"]["bodytemplate"] = `"{\"test\": }"`
 	btCfgE := fmtTestConfigResource("view", "new", nilLst, viewDefaults, btArgs, nilLst)
 
+	phArgs := map[string]map[string]string{"webhook": cloneDefaults(chnlDefaults["webhook"])}
+	phArgs["webhook"]["bodytemplate"] = `jsonencode({ summary = "{{ matchs }} matches" })`
+	phCfgE := fmtTestConfigResource("view", "new", nilLst, viewDefaults, phArgs, nilLst)
+
 	hdArgs := map[string]map[string]string{"webhook": cloneDefaults(chnlDefaults["webhook"])}
 	hdArgs["webhook"]["headers"] = `["headers", "invalid", "array"]`
 	hdCfgE := fmtTestConfigResource("view", "new", nilLst, viewDefaults, hdArgs, nilLst)
@@ -222,6 +236,10 @@ func TestView_ErrorsWebhookChannel(t *testing.T) {
 				Config:      btCfgE,
 				ExpectError: regexp.MustCompile("Error: bodytemplate is not a valid JSON string"),
 			},
+			{
+				Config:      phCfgE,
+				ExpectError: regexp.MustCompile(`unknown placeholder "{{ matchs }}" at offset`),
+			},
 			{
 				Config:      hdCfgE,
 				ExpectError: regexp.MustCompile("Inappropriate value for attribute \"headers\": map of string required"),
@@ -259,6 +277,7 @@ func TestView_Basic(t *testing.T) {
 					testViewExists("logdna_view.new"),
 					resource.TestCheckResourceAttr("logdna_view.new", "name", "test"),
 					resource.TestCheckResourceAttr("logdna_view.new", "query", "test"),
+					resource.TestMatchResourceAttr("logdna_view.new", "url", webURLPattern(`/logs/view/\w+$`)),
 				),
 			},
 			{
@@ -274,6 +293,12 @@ func TestView_Basic(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "logdna_view.new",
+				ImportState:       true,
+				ImportStateId:     "name:test2",
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -355,8 +380,8 @@ func TestView_BulkChannels(t *testing.T) {
 					resource.TestCheckResourceAttr("logdna_view.new", "name", "test"),
 					resource.TestCheckResourceAttr("logdna_view.new", "query", "test"),
 					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.#", "2"),
-					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.0.%", "9"),
-					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.1.%", "9"),
+					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.0.%", "10"),
+					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.1.%", "10"),
 					resource.TestCheckResourceAttr("logdna_view.new", "email_channel.#", "0"),
 					resource.TestCheckResourceAttr("logdna_view.new", "pagerduty_channel.#", "0"),
 					resource.TestCheckResourceAttr("logdna_view.new", "slack_channel.#", "0"),
@@ -463,7 +488,7 @@ func TestView_MultipleChannels(t *testing.T) {
 					resource.TestCheckResourceAttr("logdna_view.new", "slack_channel.0.triggerlimit", "15"),
 					resource.TestCheckResourceAttr("logdna_view.new", "slack_channel.0.url", "https://hooks.slack.com/services/identifier/secret"),
 					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.#", "1"),
-					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.0.%", "9"),
+					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.0.%", "10"),
 					// The JSON will have newlines per our API which uses JSON.stringify(obj, null, 2) as the value
 					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.0.bodytemplate", "{\n  \"fields\": {\n    \"description\": \"{{ matches }} matches found for {{ name }}\",\n    \"issuetype\": {\n      \"name\": \"Bug\"\n    },\n    \"project\": {\n      \"key\": \"test\"\n    },\n    \"summary\": \"Alert from {{ name }}\"\n  }\n}"),
 					resource.TestCheckResourceAttr("logdna_view.new", "webhook_channel.0.headers.%", "2"),
@@ -503,6 +528,115 @@ func TestView_MultipleChannels(t *testing.T) {
 	})
 }
 
+func TestView_ReorderedChannels(t *testing.T) {
+	// Built by hand since fmtResourceBlock does not guarantee the channel order
+	emailChannels := func(first, second string) string {
+		return fmt.Sprintf(`%s
+resource "logdna_view" "new" {
+	name       = "Two Email Alerts"
+	query      = "test"
+	email_channel {
+		emails          = [%q]
+		operator        = "absence"
+		terminal        = "true"
+		triggerinterval = "15m"
+		triggerlimit    = 15
+	}
+	email_channel {
+		emails          = [%q]
+		operator        = "absence"
+		terminal        = "true"
+		triggerinterval = "15m"
+		triggerlimit    = 15
+	}
+}`, fmtProviderBlock(), first, second)
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: emailChannels("first@logdna.com", "second@logdna.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testViewExists("logdna_view.new"),
+					resource.TestCheckResourceAttr("logdna_view.new", "email_channel.#", "2"),
+					resource.TestCheckResourceAttr("logdna_view.new", "email_channel.0.emails.0", "first@logdna.com"),
+					resource.TestCheckResourceAttr("logdna_view.new", "email_channel.1.emails.0", "second@logdna.com"),
+				),
+			},
+			{
+				// Swapping the blocks must not produce a plan
+				Config:             emailChannels("second@logdna.com", "first@logdna.com"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+func TestView_PresetAlert(t *testing.T) {
+	chArgs := map[string]map[string]string{"email": cloneDefaults(chnlDefaults["email"])}
+	alertCfg := fmtTestConfigResource("alert", "test", nilLst, alertDefaults, chArgs, nilLst)
+
+	conflict := cloneDefaults(rsDefaults["view"])
+	conflict["presetid"] = `"abc123"`
+	conflictCfg := fmtTestConfigResource("view", "new", nilLst, conflict, chArgs, nilLst)
+
+	preset := cloneDefaults(rsDefaults["view"])
+	preset["presetid"] = "logdna_alert.test.id"
+	presetCfg := fmt.Sprintf("%s\n%s", alertCfg, fmtResourceBlock("view", "new", preset, nilOpt, nilLst))
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      conflictCfg,
+				ExpectError: regexp.MustCompile(`"presetid": conflicts with email_channel`),
+			},
+			{
+				Config: presetCfg,
+				Check: resource.ComposeTestCheckFunc(
+					testViewExists("logdna_view.new"),
+					resource.TestCheckResourceAttrPair("logdna_view.new", "presetid", "logdna_alert.test", "id"),
+					resource.TestCheckResourceAttr("logdna_view.new", "email_channel.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "logdna_view.new",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestView_importStateByName(t *testing.T) {
+	assert := assert.New(t)
+	ts := testViewServer()
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	importID := func(id string) (string, error) {
+		d := resourceView().Data(nil)
+		d.SetId(id)
+		imported, err := resourceViewImportState(context.Background(), d, pc)
+		if err != nil {
+			return "", err
+		}
+		return imported[0].Id(), nil
+	}
+
+	id, err := importID("name:Checkout errors")
+	assert.Nil(err, "No errors")
+	assert.Equal("abc123", id, "The canonical ID is stored")
+
+	_, err = importID("name:Duplicate")
+	assert.EqualError(err, `2 views are named "Duplicate", use one of their IDs instead: [ghi789 jkl012]`)
+
+	_, err = importID("name:Missing")
+	assert.EqualError(err, `no view is named "Missing"`)
+}
+
 func testViewExists(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -5,6 +5,7 @@ package logdna
 // returned by the GET. In a perfect world, they would use the same types.
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 
@@ -12,22 +13,25 @@ import (
 )
 
 type viewResponse struct {
-	Apps     []string          `json:"apps,omitempty"`
-	Category []string          `json:"category,omitempty"`
-	Channels []channelResponse `json:"channels,omitempty"`
-	Error    string            `json:"error,omitempty"`
-	Hosts    []string          `json:"hosts,omitempty"`
-	Levels   []string          `json:"levels,omitempty"`
-	Name     string            `json:"name,omitempty"`
-	Query    string            `json:"query,omitempty"`
-	Tags     []string          `json:"tags,omitempty"`
-	ViewID   string            `json:"viewID"`
+	Apps      []string          `json:"apps,omitempty"`
+	Category  []string          `json:"category,omitempty"`
+	Channels  []channelResponse `json:"channels,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Hosts     []string          `json:"hosts,omitempty"`
+	Levels    []string          `json:"levels,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	PresetIDs []string          `json:"presetids,omitempty"`
+	Query     string            `json:"query,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	ViewID    string            `json:"viewID"`
+	resourceMetadata
 }
 
 type alertResponse struct {
 	Name     string            `json:"name,omitempty"`
 	Channels []channelResponse `json:"channels,omitempty"`
 	PresetID string            `json:"presetid"`
+	resourceMetadata
 }
 
 // channelResponse contains channel data returned from the logdna APIs
@@ -35,7 +39,8 @@ type alertResponse struct {
 // some things as strings (PUT/emails) and other times arrays (GET/emails)
 type channelResponse struct {
 	AlertID         string            `json:"alertid,omitempty"`
-	BodyTemplate    string            `json:"bodyTemplate,omitempty"`
+	BodyFormat      string            `json:"bodyFormat,omitempty"`
+	BodyTemplate    interface{}       `json:"bodyTemplate,omitempty"`
 	Emails          interface{}       `json:"emails,omitempty"`
 	Headers         map[string]string `json:"headers,omitempty"`
 	Immediate       bool              `json:"immediate,omitempty"`
@@ -48,6 +53,18 @@ type channelResponse struct {
 	TriggerLimit    int               `json:"triggerlimit,omitempty"`
 	Timezone        string            `json:"timezone,omitempty"`
 	URL             string            `json:"url,omitempty"`
+
+	// Every key returned for the channel, used for integrations that are not modeled
+	raw map[string]interface{}
+}
+
+// UnmarshalJSON decodes the channel and keeps a copy of all of its keys
+func (c *channelResponse) UnmarshalJSON(data []byte) error {
+	type plain channelResponse
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.raw)
 }
 
 type archiveResponse struct {
@@ -67,12 +84,21 @@ type archiveResponse struct {
 	Username           string `json:"username,omitempty"`
 	Password           string `json:"password,omitempty"`
 	TenantName         string `json:"tenantname,omitempty"`
+	RoleARN            string `json:"rolearn,omitempty"`
+	ExternalID         string `json:"externalid,omitempty"`
+	Region             string `json:"region,omitempty"`
+	Prefix             string `json:"prefix,omitempty"`
+	SSE                string `json:"sse,omitempty"`
+	KMSKeyID           string `json:"kmskeyid,omitempty"`
+	Status             string `json:"status,omitempty"`
+	LastError          string `json:"lasterror,omitempty"`
 }
 
 type categoryResponse struct {
 	Name string `json:"name,omitempty"`
 	Type string `json:"type,omitempty"`
 	Id   string `json:"id"`
+	resourceMetadata
 }
 
 func (view *viewResponse) MapChannelsToSchema() (map[string][]interface{}, diag.Diagnostics) {
@@ -101,6 +127,7 @@ func mapAllChannelsToSchema(
 		PAGERDUTY: make([]interface{}, 0),
 		SLACK:     make([]interface{}, 0),
 		WEBHOOK:   make([]interface{}, 0),
+		RAW:       make([]interface{}, 0),
 	}
 
 	if len(*channels) == 0 {
@@ -120,11 +147,11 @@ func mapAllChannelsToSchema(
 		case WEBHOOK:
 			prepared = mapChannelWebhook(&c)
 		default:
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Warning,
-				Summary:  fmt.Sprintf("The remote %s resource contains an unsupported integration: %s", resourceName, integration),
-				Detail:   fmt.Sprintf("%s integration ignored since it does not map to the schema", integration),
-			})
+			// Integrations that are not modeled round-trip through `raw_channel`
+			if raw := mapChannelRaw(&c, resourceName, &diags); raw != nil {
+				prepared = raw
+			}
+			integration = RAW
 		}
 		if prepared == nil {
 			continue
@@ -180,7 +207,14 @@ func mapChannelSlack(channel *channelResponse) map[string]interface{} {
 func mapChannelWebhook(channel *channelResponse) map[string]interface{} {
 	c := make(map[string]interface{})
 
-	c["bodytemplate"] = channel.BodyTemplate
+	body := webhookBodyFromResponse(channel.BodyTemplate)
+	format := channel.BodyFormat
+	if format == "" {
+		format = inferWebhookBodyFormat(body)
+	}
+
+	c["body_format"] = format
+	c["bodytemplate"] = body
 	c["headers"] = channel.Headers
 	c["immediate"] = strconv.FormatBool(channel.Immediate)
 	c["method"] = channel.Method
@@ -193,6 +227,33 @@ func mapChannelWebhook(channel *channelResponse) map[string]interface{} {
 	return c
 }
 
+func mapChannelRaw(channel *channelResponse, resourceName string, diags *diag.Diagnostics) map[string]interface{} {
+	c := make(map[string]interface{})
+
+	settings := make(map[string]interface{}, len(channel.raw))
+	for k, v := range channel.raw {
+		// The alert ID is assigned by the server and is not part of the settings
+		if k == "integration" || k == "alertid" {
+			continue
+		}
+		settings[k] = v
+	}
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("The remote %s resource contains an unsupported integration: %s", resourceName, channel.Integration),
+			Detail:   fmt.Sprintf("%s integration settings cannot be encoded: %s", channel.Integration, err),
+		})
+		return nil
+	}
+
+	c["integration"] = channel.Integration
+	c["settings"] = string(encoded)
+
+	return c
+}
+
 func appendError(err error, diags *diag.Diagnostics) *diag.Diagnostics {
 	if err != nil {
 		*diags = append(*diags, diag.Diagnostic{
@@ -1,6 +1,7 @@
 package logdna
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -11,10 +12,16 @@ import (
 func TestResponseTypes_mapAllChannelsToSchema(t *testing.T) {
 	assert := assert.New(t)
 
-	t.Run("Inserts a Diagnostics error for an unknown integration type in the response", func(t *testing.T) {
-		channels := []channelResponse{
-			{Integration: "NOPE"},
-		}
+	t.Run("Maps an unknown integration type in the response to raw_channel", func(t *testing.T) {
+		channels := []channelResponse{}
+		err := json.Unmarshal([]byte(`[{
+			"alertid": "abc123",
+			"integration": "opsgenie",
+			"key": "secret",
+			"triggerlimit": 15
+		}]`), &channels)
+		assert.Nil(err, "No errors")
+
 		channelIntegrations, diags := mapAllChannelsToSchema("view", &channels)
 
 		expected := map[string][]interface{}{
@@ -22,14 +29,15 @@ func TestResponseTypes_mapAllChannelsToSchema(t *testing.T) {
 			PAGERDUTY: make([]interface{}, 0),
 			SLACK:     make([]interface{}, 0),
 			WEBHOOK:   make([]interface{}, 0),
+			RAW: {
+				map[string]interface{}{
+					"integration": "opsgenie",
+					"settings":    `{"key":"secret","triggerlimit":15}`,
+				},
+			},
 		}
-		assert.Equal(expected, channelIntegrations, "Nothing was returned")
-		assert.Len(*diags, 1, "There was 1 diags error")
-
-		err := (*diags)[0]
-		assert.Equal(diag.Warning, err.Severity, "The level is Warning")
-		assert.Equal("The remote view resource contains an unsupported integration: NOPE", err.Summary, "Summary")
-		assert.Equal("NOPE integration ignored since it does not map to the schema", err.Detail, "Detail")
+		assert.Equal(expected, channelIntegrations, "The channel was kept as raw")
+		assert.Len(*diags, 0, "There were no diags")
 	})
 }
 
@@ -51,3 +59,19 @@ func TestResponseTypes_appendError(t *testing.T) {
 		assert.Equal("Some Error", result.Detail, "Detail")
 	})
 }
+
+func TestResponseTypes_mapChannelWebhook(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Round-trips the body format returned by the API", func(t *testing.T) {
+		c := mapChannelWebhook(&channelResponse{BodyFormat: bodyFormatText, BodyTemplate: "a=1"})
+		assert.Equal(bodyFormatText, c["body_format"])
+		assert.Equal("a=1", c["bodytemplate"])
+	})
+
+	t.Run("Encodes JSON bodies returned as values", func(t *testing.T) {
+		c := mapChannelWebhook(&channelResponse{BodyTemplate: []interface{}{"a"}})
+		assert.Equal(bodyFormatJSON, c["body_format"])
+		assert.Equal("[\n  \"a\"\n]", c["bodytemplate"])
+	})
+}
@@ -0,0 +1,260 @@
+package logdna
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Fields holding notification secrets, by integration. These are marked as
+// Sensitive in the schema, and the API may mask them (e.g. `****abcd`) on GET.
+var channelSecretFields = map[string][]string{
+	PAGERDUTY: {"key"},
+	SLACK:     {"url"},
+	WEBHOOK:   {"url", "headers"},
+}
+
+const secretMaskChar = "*"
+
+// A mask says little about the secret behind it, a fully masked value nothing
+// at all. State keeps a salted hash of the secrets of every channel that was
+// sent to the API in `channel_secret_hashes`, and a masked value is only
+// resolved to the secret in state when that secret is known to have been sent.
+var channelSecretHashesSchema = &schema.Schema{
+	Type:      schema.TypeSet,
+	Computed:  true,
+	Sensitive: true,
+	Elem:      &schema.Schema{Type: schema.TypeString},
+}
+
+func isMaskedSecret(value string) bool {
+	return strings.Contains(value, strings.Repeat(secretMaskChar, 3))
+}
+
+// secretMatchesMask reports whether `secret` is consistent with the masked value
+// returned by the API, i.e. every visible fragment of the mask appears in the
+// secret, in order, anchored at the start and end where the mask is.
+func secretMatchesMask(secret, masked string) bool {
+	if !isMaskedSecret(masked) {
+		return secret == masked
+	}
+	fragments := strings.Split(masked, secretMaskChar)
+	rest := secret
+
+	first, last := fragments[0], fragments[len(fragments)-1]
+	if !strings.HasPrefix(rest, first) {
+		return false
+	}
+	rest = rest[len(first):]
+	if !strings.HasSuffix(rest, last) {
+		return false
+	}
+	rest = rest[:len(rest)-len(last)]
+
+	for _, f := range fragments[1 : len(fragments)-1] {
+		if f == "" {
+			continue
+		}
+		idx := strings.Index(rest, f)
+		if idx == -1 {
+			return false
+		}
+		rest = rest[idx+len(f):]
+	}
+	return true
+}
+
+// channelSecret returns the secrets of a channel as a single canonical value,
+// or an empty string when the integration has no secrets
+func channelSecret(integration string, c map[string]interface{}) string {
+	fields, ok := channelSecretFields[integration]
+	if !ok {
+		return ""
+	}
+	secrets := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		switch v := c[field].(type) {
+		case string:
+			secrets[field] = v
+		case map[string]interface{}:
+			headers := make(map[string]string, len(v))
+			for k, hv := range v {
+				headers[k] = fmt.Sprintf("%v", hv)
+			}
+			secrets[field] = headers
+		case map[string]string:
+			secrets[field] = v
+		}
+	}
+	// json.Marshal sorts map keys, which makes the output stable
+	encoded, _ := json.Marshal(secrets)
+	return string(encoded)
+}
+
+// channelSecretHashes returns the hashes to store in state for the channels
+// that were just sent to the API. Hashes that still match are kept as they are
+// so that the state does not change with every apply.
+func channelSecretHashes(current []string, integrations map[string][]interface{}) []string {
+	hashes := make([]string, 0)
+	for integration, channels := range integrations {
+		for _, c := range channels {
+			cm, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			secret := channelSecret(integration, cm)
+			if secret == "" {
+				continue
+			}
+			hash := newSecretHash(secret)
+			for _, h := range current {
+				if secretMatchesHash(secret, h) {
+					hash = h
+					break
+				}
+			}
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// channelSecretWasSent reports whether the secrets of a channel match one of
+// the hashes in state
+func channelSecretWasSent(integration string, c map[string]interface{}, hashes []string) bool {
+	secret := channelSecret(integration, c)
+	for _, h := range hashes {
+		if secretMatchesHash(secret, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// getChannelSecretHashes returns the hashes in `channel_secret_hashes`, if the
+// schema has them
+func getChannelSecretHashes(d *schema.ResourceData) []string {
+	hashes, ok := d.Get("channel_secret_hashes").(*schema.Set)
+	if !ok {
+		return nil
+	}
+	return listToStrings(hashes.List())
+}
+
+// setChannelSecretHashes records the secrets of the channel blocks as sent to
+// the API
+func setChannelSecretHashes(d *schema.ResourceData) error {
+	integrations := make(map[string][]interface{}, len(channelSecretFields))
+	for integration := range channelSecretFields {
+		integrations[integration] = d.Get(fmt.Sprintf("%s_channel", integration)).([]interface{})
+	}
+	return d.Set("channel_secret_hashes", channelSecretHashes(getChannelSecretHashes(d), integrations))
+}
+
+// migrateChannelSecretHashes seeds `channel_secret_hashes` from the channels in
+// state when there are none. States written before the secrets were hashed
+// hold the secrets that were last sent.
+func migrateChannelSecretHashes(d *schema.ResourceData) error {
+	if _, ok := d.GetOk("channel_secret_hashes"); ok {
+		return nil
+	}
+	return setChannelSecretHashes(d)
+}
+
+// unmaskSecret returns the value to store in state for a secret. A masked
+// remote value that is consistent with what is in state keeps the state value,
+// otherwise the remote value is kept so that the drift is reported.
+func unmaskSecret(current, remote string) (string, bool) {
+	if !isMaskedSecret(remote) {
+		return remote, remote == current
+	}
+	if secretMatchesMask(current, remote) {
+		return current, true
+	}
+	return remote, false
+}
+
+// unmaskChannels replaces masked secrets in the remote channels of a single
+// integration with the values in state, pairing each remote channel with the
+// first unused state channel whose secrets all match the masks. Only state
+// channels whose secrets match one of `hashes`, i.e. that were sent to the API
+// as they are, are candidates.
+func unmaskChannels(integration string, current []interface{}, remote []interface{}, hashes []string) []interface{} {
+	fields, ok := channelSecretFields[integration]
+	if !ok || len(current) == 0 {
+		return remote
+	}
+
+	used := make([]bool, len(current))
+	for _, r := range remote {
+		rm := r.(map[string]interface{})
+		if !channelHasMaskedSecret(rm, fields) {
+			continue
+		}
+		for i, c := range current {
+			cm, ok := c.(map[string]interface{})
+			if used[i] || !ok || cm["operator"] != rm["operator"] || !channelSecretWasSent(integration, cm, hashes) {
+				continue
+			}
+			if unmasked, ok := unmaskChannel(cm, rm, fields); ok {
+				for k, v := range unmasked {
+					rm[k] = v
+				}
+				used[i] = true
+				break
+			}
+		}
+	}
+	return remote
+}
+
+func channelHasMaskedSecret(c map[string]interface{}, fields []string) bool {
+	for _, field := range fields {
+		switch v := c[field].(type) {
+		case string:
+			if isMaskedSecret(v) {
+				return true
+			}
+		case map[string]string:
+			for _, hv := range v {
+				if isMaskedSecret(hv) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func unmaskChannel(current, remote map[string]interface{}, fields []string) (map[string]interface{}, bool) {
+	unmasked := make(map[string]interface{})
+	for _, field := range fields {
+		switch rv := remote[field].(type) {
+		case string:
+			cv, _ := current[field].(string)
+			value, ok := unmaskSecret(cv, rv)
+			if !ok {
+				return nil, false
+			}
+			unmasked[field] = value
+		case map[string]string:
+			cv, _ := current[field].(map[string]interface{})
+			headers := make(map[string]string, len(rv))
+			for k, hv := range rv {
+				chv, exists := cv[k].(string)
+				if !exists && isMaskedSecret(hv) {
+					return nil, false
+				}
+				value, ok := unmaskSecret(chv, hv)
+				if !ok {
+					return nil, false
+				}
+				headers[k] = value
+			}
+			unmasked[field] = headers
+		}
+	}
+	return unmasked, true
+}
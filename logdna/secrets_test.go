@@ -0,0 +1,172 @@
+package logdna
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecrets_secretMatchesMask(t *testing.T) {
+	assert := assert.New(t)
+	secret := "https://hooks.slack.com/services/T000/B000/abcdefgh"
+
+	t.Run("Unmasked values are compared as-is", func(t *testing.T) {
+		assert.True(secretMatchesMask(secret, secret))
+		assert.False(secretMatchesMask(secret, "https://hooks.slack.com/services/T000/B000/other"))
+	})
+
+	t.Run("Visible prefix and suffix must match", func(t *testing.T) {
+		assert.True(secretMatchesMask(secret, "https://hooks.slack.com/services/T000/B000/****efgh"))
+		assert.True(secretMatchesMask(secret, "https://hooks.slack.com/********"))
+		assert.False(secretMatchesMask(secret, "https://hooks.slack.com/services/T000/B000/****zzzz"))
+		assert.False(secretMatchesMask(secret, "https://example.com/****"))
+	})
+
+	t.Run("A fully masked value matches anything", func(t *testing.T) {
+		assert.True(secretMatchesMask("anything", "********"))
+	})
+}
+
+func TestSecrets_unmaskChannels(t *testing.T) {
+	assert := assert.New(t)
+	sent := func(integration string, current []interface{}) []string {
+		return channelSecretHashes(nil, map[string][]interface{}{integration: current})
+	}
+
+	t.Run("Masked PagerDuty keys consistent with state keep the state value", func(t *testing.T) {
+		current := []interface{}{
+			map[string]interface{}{"key": "first-key-1234", "operator": "presence"},
+			map[string]interface{}{"key": "second-key-5678", "operator": "presence"},
+		}
+		remote := []interface{}{
+			map[string]interface{}{"key": "****5678", "operator": "presence"},
+			map[string]interface{}{"key": "****1234", "operator": "presence"},
+		}
+
+		unmasked := unmaskChannels(PAGERDUTY, current, remote, sent(PAGERDUTY, current))
+		assert.Equal("second-key-5678", unmasked[0].(map[string]interface{})["key"])
+		assert.Equal("first-key-1234", unmasked[1].(map[string]interface{})["key"])
+	})
+
+	t.Run("Masked values that do not match state are kept to surface drift", func(t *testing.T) {
+		current := []interface{}{
+			map[string]interface{}{"key": "first-key-1234", "operator": "presence"},
+		}
+		remote := []interface{}{
+			map[string]interface{}{"key": "****9999", "operator": "presence"},
+		}
+
+		unmasked := unmaskChannels(PAGERDUTY, current, remote, sent(PAGERDUTY, current))
+		assert.Equal("****9999", unmasked[0].(map[string]interface{})["key"])
+	})
+
+	t.Run("Fully masked values only match secrets that were sent", func(t *testing.T) {
+		current := []interface{}{
+			map[string]interface{}{"key": "first-key-1234", "operator": "presence"},
+		}
+		remote := func() []interface{} {
+			return []interface{}{map[string]interface{}{"key": "********", "operator": "presence"}}
+		}
+
+		unmasked := unmaskChannels(PAGERDUTY, current, remote(), sent(PAGERDUTY, current))
+		assert.Equal("first-key-1234", unmasked[0].(map[string]interface{})["key"])
+
+		unmasked = unmaskChannels(PAGERDUTY, current, remote(), nil)
+		assert.Equal("********", unmasked[0].(map[string]interface{})["key"], "Nothing was recorded as sent")
+
+		rotated := sent(PAGERDUTY, []interface{}{map[string]interface{}{"key": "rotated-key-9999"}})
+		unmasked = unmaskChannels(PAGERDUTY, current, remote(), rotated)
+		assert.Equal("********", unmasked[0].(map[string]interface{})["key"], "The secret in state is not the one sent")
+	})
+
+	t.Run("Webhook headers are unmasked individually", func(t *testing.T) {
+		current := []interface{}{
+			map[string]interface{}{
+				"url":      "https://yourwebhook/endpoint",
+				"operator": "presence",
+				"headers": map[string]interface{}{
+					"Authorization": "Bearer secret-token",
+					"Other":         "plain",
+				},
+			},
+		}
+		remote := []interface{}{
+			map[string]interface{}{
+				"url":      "https://yourwebhook/endpoint",
+				"operator": "presence",
+				"headers": map[string]string{
+					"Authorization": "Bearer ****",
+					"Other":         "plain",
+				},
+			},
+		}
+
+		unmasked := unmaskChannels(WEBHOOK, current, remote, sent(WEBHOOK, current))
+		headers := unmasked[0].(map[string]interface{})["headers"].(map[string]string)
+		assert.Equal("Bearer secret-token", headers["Authorization"])
+		assert.Equal("plain", headers["Other"])
+	})
+
+	t.Run("Masked headers unknown to state are left masked", func(t *testing.T) {
+		current := []interface{}{
+			map[string]interface{}{
+				"url":      "https://yourwebhook/endpoint",
+				"operator": "presence",
+				"headers":  map[string]interface{}{},
+			},
+		}
+		remote := []interface{}{
+			map[string]interface{}{
+				"url":      "https://yourwebhook/endpoint",
+				"operator": "presence",
+				"headers":  map[string]string{"Authorization": "****"},
+			},
+		}
+
+		unmasked := unmaskChannels(WEBHOOK, current, remote, sent(WEBHOOK, current))
+		headers := unmasked[0].(map[string]interface{})["headers"].(map[string]string)
+		assert.Equal("****", headers["Authorization"])
+	})
+}
+
+func TestSecrets_channelSecretHashes(t *testing.T) {
+	assert := assert.New(t)
+	integrations := map[string][]interface{}{
+		EMAIL: {map[string]interface{}{"emails": []interface{}{"test@logdna.com"}}},
+		WEBHOOK: {map[string]interface{}{
+			"url":     "https://yourwebhook/endpoint",
+			"headers": map[string]interface{}{"Authorization": "Bearer secret-token"},
+		}},
+	}
+
+	hashes := channelSecretHashes(nil, integrations)
+	assert.Len(hashes, 1, "Only channels with secrets are hashed")
+	assert.True(isSecretHash(hashes[0]))
+	assert.NotContains(hashes[0], "secret-token")
+	assert.True(channelSecretWasSent(WEBHOOK, integrations[WEBHOOK][0].(map[string]interface{}), hashes))
+	assert.Equal(hashes, channelSecretHashes(hashes, integrations), "Matching hashes are kept")
+
+	changed := map[string]interface{}{
+		"url":     "https://yourwebhook/endpoint",
+		"headers": map[string]interface{}{"Authorization": "Bearer other-token"},
+	}
+	assert.False(channelSecretWasSent(WEBHOOK, changed, hashes))
+}
+
+func TestSecrets_schemaIsSensitive(t *testing.T) {
+	assert := assert.New(t)
+
+	for name, rs := range map[string]*schema.Resource{
+		"logdna_view":  resourceView(),
+		"logdna_alert": resourceAlert(),
+	} {
+		for integration, fields := range channelSecretFields {
+			block := rs.Schema[fmt.Sprintf("%s_channel", integration)].Elem.(*schema.Resource)
+			for _, field := range fields {
+				assert.True(block.Schema[field].Sensitive, "%s %s_channel.%s is sensitive", name, integration, field)
+			}
+		}
+	}
+}
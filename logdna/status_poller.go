@@ -0,0 +1,61 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// statusPoller waits for a configuration that the API checks after it is
+// saved, e.g. an archive being verified or a stream being activated
+type statusPoller struct {
+	// Used in the error of a failed status, e.g. "archive verification"
+	operation string
+	pending   []string
+	target    string
+	failed    string
+	interval  time.Duration
+	// fetch returns the status of the configuration, and the reason given by
+	// the API when it failed
+	fetch func() (status string, lastError string, err error)
+}
+
+// refreshFunc reports the status returned by fetch. An empty status means that
+// the account does not report one, so there is nothing to wait for. The failed
+// status is an error, and any other status is reported as is, so that unknown
+// statuses stop the wait.
+func (p statusPoller) refreshFunc() resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		status, lastError, err := p.fetch()
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch status {
+		case "":
+			return status, p.target, nil
+		case p.failed:
+			if lastError == "" {
+				lastError = "no reason was given"
+			}
+			return status, status, fmt.Errorf("%s failed: %s", p.operation, lastError)
+		}
+		return status, status, nil
+	}
+}
+
+// wait polls the status while it is pending, until it is the target
+func (p statusPoller) wait(ctx context.Context, timeout time.Duration) error {
+	conf := &resource.StateChangeConf{
+		Pending:      p.pending,
+		Target:       []string{p.target},
+		Refresh:      p.refreshFunc(),
+		Timeout:      timeout,
+		PollInterval: p.interval,
+	}
+
+	_, err := conf.WaitForStateContext(ctx)
+	return err
+}
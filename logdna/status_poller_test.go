@@ -0,0 +1,79 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStatusServer walks a configuration through the statuses, one per GET,
+// and then keeps returning the last one. Writes return the first status. The
+// body is `format` with the status and, when it is `failed`, `lastError`.
+func testStatusServer(format, failed, lastError string, statuses ...string) (*httptest.Server, *int) {
+	var mutex sync.Mutex
+	gets := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		status := ""
+		if len(statuses) > 0 {
+			status = statuses[0]
+		}
+		if r.Method == "GET" {
+			if gets < len(statuses) {
+				status = statuses[gets]
+			} else if len(statuses) > 0 {
+				status = statuses[len(statuses)-1]
+			}
+			gets++
+		}
+
+		reason := ""
+		if status == failed {
+			reason = lastError
+		}
+		fmt.Fprintf(w, format, status, reason)
+	}))
+	return ts, &gets
+}
+
+func TestStatusPoller_wait(t *testing.T) {
+	assert := assert.New(t)
+
+	polled := 0
+	statuses := []string{"pending", "", "failed", "unknown"}
+	poller := statusPoller{
+		operation: "test",
+		pending:   []string{"pending"},
+		target:    "done",
+		failed:    "failed",
+		interval:  time.Millisecond,
+		fetch: func() (string, string, error) {
+			status := statuses[polled]
+			polled++
+			return status, "", nil
+		},
+	}
+	assert.Nil(poller.wait(context.Background(), time.Minute), "No status means nothing to wait for")
+	assert.Equal(2, polled)
+
+	err := poller.wait(context.Background(), time.Minute)
+	assert.NotNil(err, "The failed status is an error")
+	assert.Contains(err.Error(), "test failed: no reason was given")
+
+	err = poller.wait(context.Background(), time.Minute)
+	assert.NotNil(err, "Unknown statuses stop the wait")
+	assert.Contains(err.Error(), "unexpected state 'unknown'")
+
+	poller.fetch = func() (string, string, error) { return "", "", fmt.Errorf("connection refused") }
+	err = poller.wait(context.Background(), time.Minute)
+	assert.NotNil(err, "Errors of fetch stop the wait")
+	assert.Contains(err.Error(), "connection refused")
+}
@@ -0,0 +1,165 @@
+package logdna
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// SASL mechanisms the streaming API authenticates to Kafka with. Without one,
+// the API picks its default for the user and password.
+var streamSASLMechanisms = []string{"plain", "scram-sha-256", "scram-sha-512"}
+
+// Compression codecs of the messages produced to the topic
+var streamCompressions = []string{"none", "gzip", "snappy", "lz4", "zstd"}
+
+// Fields of the log lines the partition of their message is derived from
+var streamPartitionKeys = []string{"app", "host", "level"}
+
+func validateOneOf(values []string) schema.SchemaValidateFunc {
+	return func(val interface{}, key string) (warns []string, errs []error) {
+		if v := val.(string); !containsString(values, v, false) {
+			errs = append(errs, fmt.Errorf("%q must be one of %v, got: %s", key, values, v))
+		}
+		return
+	}
+}
+
+// validatePEMCertificates checks that the value is one or more PEM encoded
+// X.509 certificates, e.g. a CA bundle
+func validatePEMCertificates(val interface{}, key string) (warns []string, errs []error) {
+	rest := []byte(strings.TrimSpace(val.(string)))
+	count := 0
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			errs = append(errs, fmt.Errorf("%q must only hold PEM encoded certificates", key))
+			return
+		}
+		if block.Type != "CERTIFICATE" {
+			errs = append(errs, fmt.Errorf("%q must only hold certificates, got a PEM block of type %q", key, block.Type))
+			return
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			errs = append(errs, fmt.Errorf("%q holds an invalid certificate: %s", key, err))
+			return
+		}
+		rest = []byte(strings.TrimSpace(string(rest)))
+		count++
+	}
+	if count == 0 {
+		errs = append(errs, fmt.Errorf("%q must hold at least one PEM encoded certificate", key))
+	}
+	return
+}
+
+// validatePEMPrivateKey checks that the value is a PEM encoded private key.
+// The value is never part of the error.
+func validatePEMPrivateKey(val interface{}, key string) (warns []string, errs []error) {
+	block, rest := pem.Decode([]byte(strings.TrimSpace(val.(string))))
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") || len(strings.TrimSpace(string(rest))) > 0 {
+		errs = append(errs, fmt.Errorf("%q must be a single PEM encoded private key", key))
+	}
+	return
+}
+
+// checkStreamAuth validates the settings of the stream that depend on each
+// other. The stream authenticates with a user and password over SASL, or with
+// a client certificate over TLS; the API reports when neither is set.
+func checkStreamAuth(d *schema.ResourceDiff) error {
+	user := d.Get("user").(string)
+	password := d.Get("password").(string)
+	if (user == "") != (password == "") {
+		return fmt.Errorf("user and password must be set together")
+	}
+	if d.Get("sasl_mechanism").(string) != "" && user == "" {
+		return fmt.Errorf("sasl_mechanism requires user and password")
+	}
+
+	var tls map[string]interface{}
+	if d.Get("tls.#").(int) > 0 {
+		tls, _ = d.Get("tls.0").(map[string]interface{})
+	}
+	if tls != nil {
+		clientCert := tls["client_cert"].(string)
+		clientKey := tls["client_key"].(string)
+		if (clientCert == "") != (clientKey == "") {
+			return fmt.Errorf("tls.client_cert and tls.client_key must be set together")
+		}
+		if !tls["enabled"].(bool) && (clientCert != "" || tls["ca_cert"].(string) != "") {
+			return fmt.Errorf("tls certificates cannot be set when tls.enabled is false")
+		}
+	}
+	return nil
+}
+
+// generateStreamConfig maps the attributes of the resource to the request
+func generateStreamConfig(d *schema.ResourceData) streamConfig {
+	c := streamConfig{
+		Brokers:       listToStrings(d.Get("brokers").([]interface{})),
+		Topic:         d.Get("topic").(string),
+		User:          d.Get("user").(string),
+		Password:      d.Get("password").(string),
+		SASLMechanism: d.Get("sasl_mechanism").(string),
+		Compression:   d.Get("compression").(string),
+		PartitionKey:  d.Get("partition_key").(string),
+	}
+
+	// The TLS settings are only sent with a tls block. When the block was
+	// removed, its defaults are sent to reset the settings it had set. Without
+	// a block before or now, the API keeps its own settings.
+	o, n := d.GetChange("tls")
+	tlsRaw := n.([]interface{})
+	if len(tlsRaw) == 0 || tlsRaw[0] == nil {
+		if old := o.([]interface{}); len(old) == 0 || old[0] == nil {
+			return c
+		}
+		tlsRaw = []interface{}{map[string]interface{}{"enabled": true, "ca_cert": "", "client_cert": "", "client_key": ""}}
+	}
+
+	tls := tlsRaw[0].(map[string]interface{})
+	enabled := tls["enabled"].(bool)
+	caCert := tls["ca_cert"].(string)
+	clientCert := tls["client_cert"].(string)
+	clientKey := tls["client_key"].(string)
+	c.TLS = &enabled
+	c.TLSCACert = &caCert
+	c.TLSClientCert = &clientCert
+	c.TLSClientKey = &clientKey
+	return c
+}
+
+// isDefaultStreamTLS reports whether a flattened `tls` block only holds the
+// defaults of the block
+func isDefaultStreamTLS(tls []interface{}) bool {
+	if len(tls) == 0 {
+		return true
+	}
+	block := tls[0].(map[string]interface{})
+	return block["enabled"].(bool) && block["ca_cert"].(string) == "" && block["client_cert"].(string) == ""
+}
+
+// flattenStreamTLS returns the `tls` block, which is empty when the API does
+// not report any TLS setting. The client key is never returned.
+func flattenStreamTLS(c streamConfig) []interface{} {
+	var caCert, clientCert string
+	if c.TLSCACert != nil {
+		caCert = *c.TLSCACert
+	}
+	if c.TLSClientCert != nil {
+		clientCert = *c.TLSClientCert
+	}
+	if c.TLS == nil && caCert == "" && clientCert == "" {
+		return []interface{}{}
+	}
+	enabled := c.TLS == nil || *c.TLS
+	return []interface{}{map[string]interface{}{
+		"enabled":     enabled,
+		"ca_cert":     caCert,
+		"client_cert": clientCert,
+	}}
+}
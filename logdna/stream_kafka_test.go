@@ -0,0 +1,232 @@
+package logdna
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPEMCertificate returns a self-signed certificate and its private key
+func testPEMCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err, "No errors")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "logdna"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err, "No errors")
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err, "No errors")
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestStreamKafka_validate(t *testing.T) {
+	assert := assert.New(t)
+	cert, key := testPEMCertificate(t)
+
+	_, errs := validateOneOf(streamSASLMechanisms)("scram-sha-512", "sasl_mechanism")
+	assert.Empty(errs)
+	_, errs = validateOneOf(streamSASLMechanisms)("SCRAM-SHA-512", "sasl_mechanism")
+	assert.Len(errs, 1)
+	assert.EqualError(errs[0], `"sasl_mechanism" must be one of [plain scram-sha-256 scram-sha-512], got: SCRAM-SHA-512`)
+	_, errs = validateOneOf(streamCompressions)("zstd", "compression")
+	assert.Empty(errs)
+
+	_, errs = validatePEMCertificates(cert, "ca_cert")
+	assert.Empty(errs)
+	_, errs = validatePEMCertificates(cert+"\n"+cert, "ca_cert")
+	assert.Empty(errs, "Bundles are certificates")
+	_, errs = validatePEMCertificates("", "ca_cert")
+	assert.Len(errs, 1)
+	_, errs = validatePEMCertificates(cert+"garbage", "ca_cert")
+	assert.Len(errs, 1)
+	_, errs = validatePEMCertificates(key, "ca_cert")
+	assert.Len(errs, 1)
+	assert.EqualError(errs[0], `"ca_cert" must only hold certificates, got a PEM block of type "EC PRIVATE KEY"`)
+
+	_, errs = validatePEMPrivateKey(key, "client_key")
+	assert.Empty(errs)
+	_, errs = validatePEMPrivateKey("not a key", "client_key")
+	assert.Len(errs, 1)
+	_, errs = validatePEMPrivateKey(cert, "client_key")
+	assert.Len(errs, 1)
+	assert.NotContains(errs[0].Error(), cert, "The value is not in the error")
+}
+
+func TestStreamKafka_checkStreamAuth(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceStreamConfig()
+	cert, key := testPEMCertificate(t)
+
+	diff := func(cfg map[string]interface{}) error {
+		cfg["brokers"] = []interface{}{"broker-1.example.org:9093"}
+		cfg["topic"] = "logs"
+		_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(cfg), nil)
+		return err
+	}
+
+	assert.Nil(diff(map[string]interface{}{"user": "logdna", "password": "secret", "sasl_mechanism": "scram-sha-512"}))
+	assert.Nil(diff(map[string]interface{}{"tls": []interface{}{map[string]interface{}{"ca_cert": cert, "client_cert": cert, "client_key": key}}}))
+	assert.EqualError(diff(map[string]interface{}{"user": "logdna"}), "user and password must be set together")
+	assert.EqualError(diff(map[string]interface{}{"sasl_mechanism": "plain"}), "sasl_mechanism requires user and password")
+	assert.EqualError(
+		diff(map[string]interface{}{"tls": []interface{}{map[string]interface{}{"client_cert": cert}}}),
+		"tls.client_cert and tls.client_key must be set together",
+	)
+	assert.EqualError(
+		diff(map[string]interface{}{"tls": []interface{}{map[string]interface{}{"enabled": false, "ca_cert": cert}}}),
+		"tls certificates cannot be set when tls.enabled is false",
+	)
+}
+
+func TestStreamKafka_mapping(t *testing.T) {
+	assert := assert.New(t)
+	cert, key := testPEMCertificate(t)
+
+	var sent map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			assert.Nil(json.NewDecoder(r.Body).Decode(&sent))
+		}
+		fmt.Fprintf(w, `{
+			"brokers": ["broker-1.example.org:9093"],
+			"topic": "logs",
+			"status": "active",
+			"saslmechanism": "",
+			"tls": true,
+			"tlscacert": %q,
+			"tlsclientcert": %q,
+			"compression": "zstd",
+			"partitionkey": "app"
+		}`, cert, cert)
+	}))
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	tls := map[string]interface{}{
+		"enabled":     true,
+		"ca_cert":     cert,
+		"client_cert": cert,
+		"client_key":  key,
+	}
+	d := schema.TestResourceDataRaw(t, resourceStreamConfig().Schema, map[string]interface{}{
+		"brokers":       []interface{}{"broker-1.example.org:9093"},
+		"topic":         "logs",
+		"tls":           []interface{}{tls},
+		"compression":   "zstd",
+		"partition_key": "app",
+	})
+	assert.False(resourceStreamConfigCreate(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(map[string]interface{}{
+		"brokers":       []interface{}{"broker-1.example.org:9093"},
+		"topic":         "logs",
+		"tls":           true,
+		"tlscacert":     cert,
+		"tlsclientcert": cert,
+		"tlsclientkey":  key,
+		"compression":   "zstd",
+		"partitionkey":  "app",
+	}, sent, "Unset options are not sent")
+
+	assert.False(resourceStreamConfigRead(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal([]interface{}{tls}, d.Get("tls"), "The client key is kept")
+	assert.Equal("zstd", d.Get("compression"))
+	assert.Equal("app", d.Get("partition_key"))
+
+	// A disabled TLS is sent
+	d = schema.TestResourceDataRaw(t, resourceStreamConfig().Schema, map[string]interface{}{
+		"tls": []interface{}{map[string]interface{}{"enabled": false}},
+	})
+	c := generateStreamConfig(d)
+	assert.NotNil(c.TLS)
+	assert.False(*c.TLS)
+
+	// The defaults read back do not add a block, other settings do
+	enabled := true
+	d = schema.TestResourceDataRaw(t, resourceStreamConfig().Schema, map[string]interface{}{})
+	setStreamConfig(streamConfig{TLS: &enabled}, d, &diag.Diagnostics{})
+	assert.Equal([]interface{}{}, d.Get("tls"))
+	setStreamConfig(streamConfig{TLS: &enabled, TLSCACert: &cert}, d, &diag.Diagnostics{})
+	assert.Equal(1, d.Get("tls.#"), "Drift from the defaults is reported")
+
+	assert.Equal([]interface{}{}, flattenStreamTLS(streamConfig{}), "No TLS settings, no block")
+}
+
+func TestStreamKafka_removeTLS(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceStreamConfig()
+	cert, _ := testPEMCertificate(t)
+
+	state := &terraform.InstanceState{ID: streamConfigID, Attributes: map[string]string{
+		"brokers.#":         "1",
+		"brokers.0":         "broker-1.example.org:9093",
+		"topic":             "logs",
+		"tls.#":             "1",
+		"tls.0.enabled":     "true",
+		"tls.0.ca_cert":     cert,
+		"tls.0.client_cert": "",
+		"tls.0.client_key":  "",
+	}}
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"brokers": []interface{}{"broker-1.example.org:9093"},
+		"topic":   "logs",
+	}), nil)
+	assert.Nil(err)
+	assert.NotNil(diff, "Removing the block is planned")
+	assert.Equal("0", diff.Attributes["tls.#"].New)
+
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	assert.Nil(err)
+	c := generateStreamConfig(d)
+	assert.True(*c.TLS)
+	assert.Equal("", *c.TLSCACert, "The CA certificate is cleared")
+	assert.Equal("", *c.TLSClientCert)
+	assert.Equal("", *c.TLSClientKey)
+}
+
+func TestStreamKafka_noTLS(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			assert.Nil(json.NewDecoder(r.Body).Decode(&sent))
+		}
+		fmt.Fprint(w, `{"brokers": ["broker-1.example.org:9093"], "topic": "logs", "status": "active", "tls": false}`)
+	}))
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	d := schema.TestResourceDataRaw(t, resourceStreamConfig().Schema, map[string]interface{}{
+		"brokers":  []interface{}{"broker-1.example.org:9093"},
+		"topic":    "logs",
+		"user":     "user",
+		"password": "password",
+	})
+	assert.False(resourceStreamConfigCreate(context.Background(), d, pc).HasError(), "No errors")
+	for _, field := range []string{"tls", "tlscacert", "tlsclientcert", "tlsclientkey"} {
+		assert.NotContains(sent, field, "No TLS setting is sent without a tls block")
+	}
+	assert.Equal(1, d.Get("tls.#"), "A TLS setting that differs from the defaults is reported")
+}
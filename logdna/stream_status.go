@@ -0,0 +1,55 @@
+package logdna
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Statuses of the stream. The API connects to the brokers after the
+// configuration is saved, and reports whether it could produce to the topic.
+const (
+	streamStatusPending    = "pending"
+	streamStatusConnecting = "connecting"
+	streamStatusActive     = "active"
+	streamStatusFailed     = "failed"
+)
+
+// Statuses of a stream that is still being activated
+var streamPendingStatuses = []string{streamStatusPending, streamStatusConnecting}
+
+// How often the stream status is polled while it is being activated
+var streamPollInterval = 5 * time.Second
+
+const streamDefaultTimeout = 5 * time.Minute
+
+// waitForStreamActivation polls the stream while it is pending, until it is
+// active. A failed or unknown status is an error.
+func waitForStreamActivation(ctx context.Context, pc *providerConfig, timeout time.Duration) error {
+	poller := statusPoller{
+		operation: "stream activation",
+		pending:   streamPendingStatuses,
+		target:    streamStatusActive,
+		failed:    streamStatusFailed,
+		interval:  streamPollInterval,
+		fetch: func() (string, string, error) {
+			c, err := getStreamConfig(pc)
+			if err != nil {
+				return "", "", err
+			}
+			return c.Status, c.LastError, nil
+		},
+	}
+	return poller.wait(ctx, timeout)
+}
+
+// streamActivationError fails the apply. The failed status and its reason are
+// still read into state.
+func streamActivationError(err error) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "The stream could not be activated",
+		Detail:   err.Error(),
+	}
+}
@@ -0,0 +1,90 @@
+package logdna
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStreamStatusServer walks the stream through the statuses, one per GET,
+// and then keeps returning the last one. Writes return the first status.
+func testStreamStatusServer(statuses ...string) (*httptest.Server, *int) {
+	return testStatusServer(
+		`{"brokers": ["broker-1.example.org:9093"], "topic": "logs", "user": "logdna", "status": %q, "lasterror": %q}`,
+		streamStatusFailed,
+		"Failed to connect to Kafka broker broker-1.example.org:9093",
+		statuses...,
+	)
+}
+
+func TestStreamStatus_waitForStreamActivation(t *testing.T) {
+	assert := assert.New(t)
+	defer func(interval time.Duration) { streamPollInterval = interval }(streamPollInterval)
+	streamPollInterval = time.Millisecond
+
+	ts, gets := testStreamStatusServer(streamStatusPending, streamStatusConnecting, streamStatusActive)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	assert.Nil(waitForStreamActivation(context.Background(), pc, time.Minute), "The stream becomes active")
+	assert.Equal(3, *gets, "Every status is polled")
+
+	ts, _ = testStreamStatusServer(streamStatusPending, streamStatusFailed)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	err := waitForStreamActivation(context.Background(), pc, time.Minute)
+	assert.NotNil(err, "The stream fails")
+	assert.Contains(err.Error(), "stream activation failed: Failed to connect to Kafka broker broker-1.example.org:9093")
+
+	ts, _ = testStreamStatusServer(streamStatusConnecting)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	err = waitForStreamActivation(context.Background(), pc, 50*time.Millisecond)
+	assert.NotNil(err, "The activation times out")
+	assert.Contains(err.Error(), "timeout while waiting for state to become 'active'")
+
+	ts, gets = testStreamStatusServer(streamStatusPending, "paused")
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	err = waitForStreamActivation(context.Background(), pc, time.Minute)
+	assert.NotNil(err, "Unknown statuses fail")
+	assert.Contains(err.Error(), "unexpected state 'paused'")
+	assert.Equal(2, *gets, "The wait stops at the unknown status")
+}
+
+func TestStreamStatus_apply(t *testing.T) {
+	assert := assert.New(t)
+	defer func(interval time.Duration) { streamPollInterval = interval }(streamPollInterval)
+	streamPollInterval = time.Millisecond
+
+	cfg := map[string]interface{}{
+		"brokers":  []interface{}{"broker-1.example.org:9093"},
+		"topic":    "logs",
+		"user":     "logdna",
+		"password": "secret",
+	}
+
+	ts, _ := testStreamStatusServer(streamStatusPending, streamStatusFailed)
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	d := schema.TestResourceDataRaw(t, resourceStreamConfig().Schema, cfg)
+	diags := resourceStreamConfigCreate(context.Background(), d, pc)
+	assert.True(diags.HasError(), "The apply fails")
+	assert.Equal("The stream could not be activated", diags[0].Summary)
+	assert.Contains(diags[0].Detail, "Failed to connect to Kafka broker")
+	assert.Equal(streamConfigID, d.Id(), "The stream was saved")
+	assert.Equal(streamStatusFailed, d.Get("status"))
+	assert.Equal("Failed to connect to Kafka broker broker-1.example.org:9093", d.Get("last_error"))
+
+	ts, gets := testStreamStatusServer(streamStatusPending, streamStatusPending, streamStatusActive)
+	defer ts.Close()
+	pc = &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	d = schema.TestResourceDataRaw(t, resourceStreamConfig().Schema, cfg)
+	assert.False(resourceStreamConfigUpdate(context.Background(), d, pc).HasError(), "No errors")
+	assert.Equal(4, *gets, "The stream is polled until active, then read")
+	assert.Equal(streamStatusActive, d.Get("status"))
+	assert.Equal("", d.Get("last_error"))
+}
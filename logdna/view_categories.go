@@ -0,0 +1,154 @@
+package logdna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Views reference categories by name in the API. `category_ids` lets users
+// reference `logdna_category` resources by ID instead, which survives renames
+// and gives Terraform a dependency to order operations with.
+
+const viewCategoryType = "views"
+
+// validateViewCategoryId checks that the value is a `logdna_category` ID
+// (`type:id`) and that the category can hold views
+func validateViewCategoryId(val interface{}, key string) (warns []string, errs []error) {
+	categoryType, _, err := parseCategoryId(val.(string))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q: %s", key, err))
+		return
+	}
+	if categoryType != viewCategoryType {
+		errs = append(errs, fmt.Errorf("%q: category %q is of type %q, only %q categories can hold views", key, val, categoryType, viewCategoryType))
+	}
+	return
+}
+
+func getCategory(pc *providerConfig, categoryType, categoryId string) (*categoryResponse, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		fmt.Sprintf("/v1/config/categories/%s/%s", categoryType, categoryId),
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] GET categories raw response body %s\n", body)
+	if err != nil {
+		return nil, err
+	}
+
+	category := categoryResponse{}
+	if err := json.Unmarshal(body, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func listCategories(pc *providerConfig, categoryType string) ([]categoryResponse, error) {
+	req := newRequestConfig(
+		pc,
+		"GET",
+		fmt.Sprintf("/v1/config/categories/%s", categoryType),
+		nil,
+	)
+
+	body, err := req.MakeRequest()
+	log.Printf("[DEBUG] GET categories list raw response body %s\n", body)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []categoryResponse{}
+	if err := json.Unmarshal(body, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// resolveViewCategoryNames returns the names the API expects for the given
+// category IDs
+func resolveViewCategoryNames(pc *providerConfig, ids []string) ([]string, error) {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		categoryType, categoryId, err := parseCategoryId(id)
+		if err != nil {
+			return nil, err
+		}
+		category, err := getCategory(pc, categoryType, categoryId)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve category %q: %s", id, err)
+		}
+		names = append(names, category.Name)
+	}
+	return names, nil
+}
+
+// resolveViewRequestCategories replaces the categories of the request with the
+// names of `category_ids`, when they are used
+func resolveViewRequestCategories(pc *providerConfig, d *schema.ResourceData, view *viewRequest) error {
+	ids := listToStrings(d.Get("category_ids").([]interface{}))
+	if len(ids) == 0 {
+		return nil
+	}
+	names, err := resolveViewCategoryNames(pc, ids)
+	if err != nil {
+		return err
+	}
+	view.Category = names
+	return nil
+}
+
+// viewCategoryIdsFromNames maps the category names returned for a view back to
+// IDs. Names without a matching category are left out, which shows as a diff.
+func viewCategoryIdsFromNames(pc *providerConfig, names []string) ([]string, error) {
+	categories, err := listCategories(pc, viewCategoryType)
+	if err != nil {
+		return nil, err
+	}
+	return matchCategoryIds(categories, names), nil
+}
+
+func matchCategoryIds(categories []categoryResponse, names []string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, category := range categories {
+			if strings.EqualFold(category.Name, name) {
+				ids = append(ids, fmt.Sprintf("%s:%s", viewCategoryType, category.Id))
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// customizeViewCategoryIds reports at plan time the `category_ids` that do not
+// exist. IDs that are not known yet (e.g. categories created in the same
+// apply) are checked when the view is created instead.
+func customizeViewCategoryIds(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("category_ids") || !d.NewValueKnown("category_ids") {
+		return nil
+	}
+	ids := listToStrings(d.Get("category_ids").([]interface{}))
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pc := m.(*providerConfig)
+	for _, id := range ids {
+		categoryType, categoryId, err := parseCategoryId(id)
+		if err != nil {
+			return err
+		}
+		if _, err := getCategory(pc, categoryType, categoryId); err != nil {
+			return fmt.Errorf("category %q referenced in category_ids does not exist: %s", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package logdna
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCategoryServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/config/categories/views":
+			fmt.Fprint(w, `[{"id": "abc", "name": "Checkout", "type": "views"}, {"id": "def", "name": "Payments", "type": "views"}]`)
+		case "/v1/config/categories/views/abc":
+			fmt.Fprint(w, `{"id": "abc", "name": "Checkout", "type": "views"}`)
+		case "/v1/config/categories/views/def":
+			fmt.Fprint(w, `{"id": "def", "name": "Payments", "type": "views"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": "not found"}`)
+		}
+	}))
+}
+
+func TestViewCategories_validateViewCategoryId(t *testing.T) {
+	assert := assert.New(t)
+
+	_, errs := validateViewCategoryId("views:abc", "category_ids.0")
+	assert.Empty(errs, "No errors")
+
+	_, errs = validateViewCategoryId("abc", "category_ids.0")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.EqualError(errs[0], `"category_ids.0": Unexpected format of category ID (abc), expected Type:Id`)
+
+	_, errs = validateViewCategoryId("boards:abc", "category_ids.1")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.EqualError(errs[0], `"category_ids.1": category "boards:abc" is of type "boards", only "views" categories can hold views`)
+}
+
+func TestViewCategories_resolve(t *testing.T) {
+	assert := assert.New(t)
+	ts := testCategoryServer()
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+
+	names, err := resolveViewCategoryNames(pc, []string{"views:def", "views:abc"})
+	assert.Nil(err, "No errors")
+	assert.Equal([]string{"Payments", "Checkout"}, names)
+
+	_, err = resolveViewCategoryNames(pc, []string{"views:nope"})
+	assert.NotNil(err, "Missing categories are an error")
+
+	ids, err := viewCategoryIdsFromNames(pc, []string{"CHECKOUT", "Deleted", "Payments"})
+	assert.Nil(err, "No errors")
+	assert.Equal([]string{"views:abc", "views:def"}, ids, "Names are matched case-insensitively")
+}
+
+func TestViewCategories_customizeViewCategoryIds(t *testing.T) {
+	assert := assert.New(t)
+	ts := testCategoryServer()
+	defer ts.Close()
+	pc := &providerConfig{serviceKey: "abc123", baseURL: ts.URL}
+	r := resourceView()
+
+	_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":         "test",
+		"category_ids": []interface{}{"views:abc", "views:def"},
+	}), pc)
+	assert.Nil(err, "No errors")
+
+	_, err = r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":         "test",
+		"category_ids": []interface{}{"views:abc", "views:nope"},
+	}), pc)
+	assert.NotNil(err, "There is an error")
+	assert.Contains(err.Error(), `category "views:nope" referenced in category_ids does not exist`)
+}
+
+func TestView_CategoryIds(t *testing.T) {
+	catArgs := map[string]string{
+		"name": `"test-category"`,
+		"type": `"views"`,
+	}
+	ctgCfg := fmtTestConfigResource("category", "test", nilLst, catArgs, nilOpt, nilLst)
+
+	rsArgs := cloneDefaults(rsDefaults["view"])
+	rsArgs["category_ids"] = "[logdna_category.test.id]"
+	viewCfg := fmt.Sprintf("%s\n%s", ctgCfg, fmtResourceBlock("view", "new", rsArgs, nilOpt, nilLst))
+
+	renamed := cloneDefaults(catArgs)
+	renamed["name"] = `"renamed-category"`
+	renamedCfg := fmt.Sprintf("%s\n%s",
+		fmtTestConfigResource("category", "test", nilLst, renamed, nilOpt, nilLst),
+		fmtResourceBlock("view", "new", rsArgs, nilOpt, nilLst),
+	)
+
+	missing := cloneDefaults(rsDefaults["view"])
+	missing["category_ids"] = `["views:does-not-exist"]`
+	missingCfg := fmtTestConfigResource("view", "new", nilLst, missing, nilOpt, nilLst)
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      missingCfg,
+				ExpectError: regexp.MustCompile(`category "views:does-not-exist" referenced in category_ids does not exist`),
+			},
+			{
+				Config: viewCfg,
+				Check: resource.ComposeTestCheckFunc(
+					testViewExists("logdna_view.new"),
+					resource.TestCheckResourceAttr("logdna_view.new", "category_ids.#", "1"),
+					resource.TestCheckResourceAttrPair("logdna_view.new", "category_ids.0", "logdna_category.test", "id"),
+				),
+			},
+			{
+				// Renaming the category does not break the view
+				Config: renamedCfg,
+				Check: resource.ComposeTestCheckFunc(
+					testViewExists("logdna_view.new"),
+					resource.TestCheckResourceAttrPair("logdna_view.new", "category_ids.0", "logdna_category.test", "id"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,122 @@
+package logdna
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// viewLevels are the log levels recognized by LogDNA, in lower case.
+// See https://docs.logdna.com/docs/log-parsing
+var viewLevels = []string{
+	"trace", "debug", "info", "notice", "warn", "warning", "error", "err",
+	"critical", "crit", "alert", "fatal", "severe", "emerg", "emergency",
+}
+
+// viewLevelAliases maps the alternative spellings of a level to the level sent
+// to the API
+var viewLevelAliases = map[string]string{
+	"warning": "warn",
+	"err":     "error",
+	"crit":    "critical",
+	"emerg":   "emergency",
+}
+
+// canonicalViewLevel returns the level as it is sent to the API: in lower case,
+// and without aliases
+func canonicalViewLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if canonical, ok := viewLevelAliases[level]; ok {
+		return canonical
+	}
+	return level
+}
+
+// canonicalViewLevels maps every level of the list to its canonical form
+func canonicalViewLevels(levels []string) []string {
+	canonical := make([]string, 0, len(levels))
+	for _, level := range levels {
+		canonical = append(canonical, canonicalViewLevel(level))
+	}
+	return canonical
+}
+
+func validateViewLevel(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	for _, level := range viewLevels {
+		if strings.EqualFold(v, level) {
+			return
+		}
+	}
+	errs = append(errs, fmt.Errorf("%q: unknown level %q, expected one of %v", key, v, viewLevels))
+	return
+}
+
+// validateNotEmpty rejects empty list elements, which would otherwise be
+// dropped from the request and show up as a perpetual diff
+func validateNotEmpty(val interface{}, key string) (warns []string, errs []error) {
+	if strings.TrimSpace(val.(string)) == "" {
+		errs = append(errs, fmt.Errorf("%q must not be empty", key))
+	}
+	return
+}
+
+// suppressStringListReorder returns a DiffSuppressFunc for lists of strings
+// whose order carries no meaning. With `foldCase`, the values are compared
+// case-insensitively as well.
+func suppressStringListReorder(foldCase bool) schema.SchemaDiffSuppressFunc {
+	return suppressListReorder(func(list []interface{}) []string {
+		return sortedStrings(list, foldCase)
+	})
+}
+
+// suppressViewLevelsReorder is the DiffSuppressFunc of `levels`, which are
+// compared in their canonical form, regardless of their order
+var suppressViewLevelsReorder = suppressListReorder(sortedViewLevels)
+
+// suppressListReorder returns a DiffSuppressFunc that compares the old and new
+// lists once sorted by `sorted`
+func suppressListReorder(sorted func([]interface{}) []string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		// The count changed, this cannot be a reorder
+		if strings.HasSuffix(k, ".#") && old != new {
+			return false
+		}
+
+		list := strings.SplitN(k, ".", 2)[0]
+		o, n := d.GetChange(list)
+		oldValues := sorted(o.([]interface{}))
+		newValues := sorted(n.([]interface{}))
+
+		shouldSuppress := strings.Join(oldValues, "\n") == strings.Join(newValues, "\n")
+		log.Printf("[DEBUG] Does %s only differ by order between state and config? %t", list, shouldSuppress)
+		return shouldSuppress
+	}
+}
+
+// sortedViewLevels returns the canonical levels of the list, sorted
+func sortedViewLevels(list []interface{}) []string {
+	levels := make([]string, 0, len(list))
+	for _, elem := range list {
+		s, _ := elem.(string)
+		levels = append(levels, canonicalViewLevel(s))
+	}
+	sort.Strings(levels)
+	return levels
+}
+
+func sortedStrings(list []interface{}, foldCase bool) []string {
+	strs := make([]string, 0, len(list))
+	for _, elem := range list {
+		s, _ := elem.(string)
+		if foldCase {
+			s = strings.ToLower(s)
+		}
+		strs = append(strs, s)
+	}
+	sort.Strings(strs)
+	return strs
+}
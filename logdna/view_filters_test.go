@@ -0,0 +1,141 @@
+package logdna
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewFilters_validateViewLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, level := range []string{"error", "ERROR", "Err", "warning", "fatal"} {
+		_, errs := validateViewLevel(level, "levels.0")
+		assert.Empty(errs, level)
+	}
+
+	_, errs := validateViewLevel("errror", "levels.1")
+	assert.Len(errs, 1, "There was 1 error")
+	assert.Contains(errs[0].Error(), `"levels.1": unknown level "errror", expected one of [trace debug`)
+}
+
+func TestViewFilters_canonicalViewLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	for level, canonical := range map[string]string{
+		"error":   "error",
+		"ERROR":   "error",
+		"Err":     "error",
+		"warning": "warn",
+		"WARN":    "warn",
+		"crit":    "critical",
+		"emerg":   "emergency",
+		"Fatal":   "fatal",
+	} {
+		assert.Equal(canonical, canonicalViewLevel(level), level)
+	}
+}
+
+func TestViewFilters_levelsRequest(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceView()
+
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":   "test",
+		"levels": []interface{}{"ERR", "warning"},
+	}), nil)
+	assert.Nil(err, "No errors")
+	assert.Equal("error", diff.Attributes["levels.0"].New, "The planned level is canonical")
+	assert.Equal("warn", diff.Attributes["levels.1"].New, "The planned level is canonical")
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"levels": []interface{}{"ERR", "warning"},
+	})
+	view := viewRequest{}
+	assert.False(view.CreateRequestBody(d).HasError(), "No errors")
+	assert.Equal([]string{"error", "warn"}, view.Levels, "The levels are sent in their canonical form")
+}
+
+func TestViewFilters_validate(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceView()
+
+	diags := r.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":   "test",
+		"levels": []interface{}{"error", "nope"},
+		"apps":   []interface{}{"api", ""},
+	}))
+	assert.Len(diags, 2, "There were 2 errors")
+	for _, d := range diags {
+		assert.Contains([]string{
+			`"levels.1": unknown level "nope", expected one of [trace debug info notice warn warning error err critical crit alert fatal severe emerg emergency]`,
+			`"apps.1" must not be empty`,
+		}, d.Summary)
+	}
+}
+
+func TestViewFilters_suppressStringListReorder(t *testing.T) {
+	assert := assert.New(t)
+	r := resourceView()
+	state := &terraform.InstanceState{
+		ID: "abc123",
+		Attributes: map[string]string{
+			"id":                      "abc123",
+			"name":                    "test",
+			"unknown_channels":        unknownChannelsKeep,
+			"manage_channels":         manageChannelsAuthoritative,
+			"channel_secret_hashes.#": "0",
+			"apps.#":                  "2",
+			"apps.0":                  "api",
+			"apps.1":                  "web",
+			"levels.#":                "2",
+			"levels.0":                "error",
+			"levels.1":                "fatal",
+		},
+	}
+	diffFor := func(config map[string]interface{}) *terraform.InstanceDiff {
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+		assert.Nil(err, "No errors")
+		return diff
+	}
+
+	t.Run("Reordering and changing the case of levels is not a diff", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{
+			"name":   "test",
+			"apps":   []interface{}{"web", "api"},
+			"levels": []interface{}{"FATAL", "Error"},
+		})
+		assert.True(diff == nil || diff.Empty(), "There is no diff")
+	})
+
+	t.Run("Aliases of the levels are not a diff", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{
+			"name":   "test",
+			"apps":   []interface{}{"api", "web"},
+			"levels": []interface{}{"FATAL", "err"},
+		})
+		assert.True(diff == nil || diff.Empty(), "There is no diff")
+	})
+
+	t.Run("Apps are case-sensitive", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{
+			"name":   "test",
+			"apps":   []interface{}{"WEB", "api"},
+			"levels": []interface{}{"error", "fatal"},
+		})
+		assert.False(diff == nil || diff.Empty(), "There is a diff")
+	})
+
+	t.Run("Removing a value is a diff", func(t *testing.T) {
+		diff := diffFor(map[string]interface{}{
+			"name":   "test",
+			"apps":   []interface{}{"api", "web"},
+			"levels": []interface{}{"error"},
+		})
+		assert.Equal("1", diff.Attributes["levels.#"].New, "levels has 1 value")
+	})
+}
@@ -0,0 +1,151 @@
+package logdna
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Formats accepted for the body of a webhook channel
+const (
+	bodyFormatJSON = "json"
+	bodyFormatText = "text"
+	bodyFormatForm = "form"
+)
+
+var webhookBodyFormats = []string{bodyFormatJSON, bodyFormatText, bodyFormatForm}
+
+func validateWebhookBodyFormat(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	for _, format := range webhookBodyFormats {
+		if v == format {
+			return
+		}
+	}
+	errs = append(errs, fmt.Errorf("%q must be one of %v, got: %s", key, webhookBodyFormats, v))
+	return
+}
+
+// prepareWebhookBody converts the raw `bodytemplate` into the value sent to the
+// API. JSON bodies may be any JSON value (object, array, string...), while text
+// and form bodies are sent as-is.
+func prepareWebhookBody(format, raw string) (interface{}, error) {
+	switch format {
+	case bodyFormatText:
+		return raw, nil
+	case bodyFormatForm:
+		if _, err := url.ParseQuery(raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	default:
+		var bt interface{}
+		// See if the JSON is valid, but don't use the value or it will double encode
+		if err := json.Unmarshal([]byte(raw), &bt); err != nil {
+			return nil, err
+		}
+		return bt, nil
+	}
+}
+
+// webhookBodyFromResponse returns the body as a string regardless of whether
+// the API sent it back as a string or as a JSON value
+func webhookBodyFromResponse(body interface{}) string {
+	switch b := body.(type) {
+	case nil:
+		return ""
+	case string:
+		return b
+	default:
+		encoded, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", b)
+		}
+		return string(encoded)
+	}
+}
+
+// inferWebhookBodyFormat guesses the format of a body when the API does not
+// return it: JSON if it parses, form if it is a query string, text otherwise
+func inferWebhookBodyFormat(body string) string {
+	var parsed interface{}
+	if body == "" || json.Unmarshal([]byte(body), &parsed) == nil {
+		return bodyFormatJSON
+	}
+	if !strings.ContainsAny(body, " \t\n") && strings.Contains(body, "=") {
+		if _, err := url.ParseQuery(body); err == nil {
+			return bodyFormatForm
+		}
+	}
+	return bodyFormatText
+}
+
+// keepWebhookBodyFormats keeps the `body_format` of the webhook channels in
+// `current` for the matching remote channels whose body is also valid in that
+// format. The API may not return the format, in which case it is inferred, and
+// a body such as `status=down` is a valid form as well as valid text.
+func keepWebhookBodyFormats(current []interface{}, remote []interface{}) []interface{} {
+	formats := make(map[string]string, len(current))
+	for _, c := range current {
+		if cm, ok := c.(map[string]interface{}); ok {
+			format, _ := cm["body_format"].(string)
+			formats[channelIdentity(WEBHOOK, cm)] = format
+		}
+	}
+
+	for _, r := range remote {
+		rm := r.(map[string]interface{})
+		format, ok := formats[channelIdentity(WEBHOOK, rm)]
+		if !ok || format == "" || format == rm["body_format"] {
+			continue
+		}
+		body, _ := rm["bodytemplate"].(string)
+		if _, err := prepareWebhookBody(format, body); err == nil {
+			rm["body_format"] = format
+		}
+	}
+	return remote
+}
+
+// webhookBodiesEqual compares two bodies according to their format, ignoring
+// whitespace for JSON, parameter order for forms and trailing newlines for text
+func webhookBodiesEqual(format, old, new string) bool {
+	switch format {
+	case bodyFormatText:
+		return strings.TrimRight(old, "\r\n") == strings.TrimRight(new, "\r\n")
+	case bodyFormatForm:
+		oldValues, err := url.ParseQuery(old)
+		if err != nil {
+			return false
+		}
+		newValues, err := url.ParseQuery(new)
+		if err != nil {
+			return false
+		}
+		return reflect.DeepEqual(oldValues, newValues)
+	default:
+		var jsonOld, jsonNew interface{}
+		if err := json.Unmarshal([]byte(old), &jsonOld); err != nil {
+			return false
+		}
+		if err := json.Unmarshal([]byte(new), &jsonNew); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(jsonNew, jsonOld)
+	}
+}
+
+// suppressWebhookBodyDiff compares `bodytemplate` using the `body_format` of
+// the same channel. Without this, `terraform apply` will think values are
+// different from remote to state because of formatting alone.
+func suppressWebhookBodyDiff(k, old, new string, d *schema.ResourceData) bool {
+	format, _ := d.Get(strings.TrimSuffix(k, "bodytemplate") + "body_format").(string)
+	shouldSuppress := webhookBodiesEqual(format, old, new)
+	log.Printf("[DEBUG] Does %q (%s) in state appear the same as remote? %t", k, format, shouldSuppress)
+	return shouldSuppress
+}
@@ -0,0 +1,104 @@
+package logdna
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookBody_prepareWebhookBody(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("JSON bodies can be arrays", func(t *testing.T) {
+		body, err := prepareWebhookBody(bodyFormatJSON, `[{"name": "{{ name }}"}]`)
+		assert.Nil(err, "No errors")
+		assert.Equal([]interface{}{map[string]interface{}{"name": "{{ name }}"}}, body)
+	})
+
+	t.Run("Invalid JSON is an error", func(t *testing.T) {
+		_, err := prepareWebhookBody(bodyFormatJSON, `{"test": }`)
+		assert.NotNil(err, "There was an error")
+	})
+
+	t.Run("Text bodies are sent as-is", func(t *testing.T) {
+		body, err := prepareWebhookBody(bodyFormatText, "Alert from {{ name }}")
+		assert.Nil(err, "No errors")
+		assert.Equal("Alert from {{ name }}", body)
+	})
+
+	t.Run("Form bodies must be form-urlencoded", func(t *testing.T) {
+		body, err := prepareWebhookBody(bodyFormatForm, "text={{ name }}&count={{ matches }}")
+		assert.Nil(err, "No errors")
+		assert.Equal("text={{ name }}&count={{ matches }}", body)
+
+		_, err = prepareWebhookBody(bodyFormatForm, "text=%zz")
+		assert.NotNil(err, "There was an error")
+	})
+}
+
+func TestWebhookBody_webhookBodiesEqual(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(webhookBodiesEqual(bodyFormatJSON, `{"a":1,"b":[1,2]}`, "{\n  \"b\": [1, 2],\n  \"a\": 1\n}"), "JSON ignores whitespace")
+	assert.False(webhookBodiesEqual(bodyFormatJSON, `[1,2]`, `[2,1]`), "JSON arrays are ordered")
+	assert.True(webhookBodiesEqual(bodyFormatForm, "a=1&b=2", "b=2&a=1"), "Form ignores parameter order")
+	assert.False(webhookBodiesEqual(bodyFormatForm, "a=1&b=2", "a=1&b=3"), "Form compares values")
+	assert.True(webhookBodiesEqual(bodyFormatText, "Alert\n", "Alert"), "Text ignores trailing newlines")
+	assert.False(webhookBodiesEqual(bodyFormatText, "Alert", "alert"), "Text is otherwise exact")
+}
+
+func TestWebhookBody_fromResponse(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("String bodies are returned as-is", func(t *testing.T) {
+		assert.Equal("a=1", webhookBodyFromResponse("a=1"))
+	})
+
+	t.Run("JSON values are encoded", func(t *testing.T) {
+		assert.Equal("[\n  1,\n  2\n]", webhookBodyFromResponse([]interface{}{1, 2}))
+	})
+
+	t.Run("The format is inferred when missing", func(t *testing.T) {
+		assert.Equal(bodyFormatJSON, inferWebhookBodyFormat(""))
+		assert.Equal(bodyFormatJSON, inferWebhookBodyFormat(`["a"]`))
+		assert.Equal(bodyFormatForm, inferWebhookBodyFormat("a=1&b=2"))
+		assert.Equal(bodyFormatText, inferWebhookBodyFormat("Alert from {{ name }}"))
+	})
+}
+
+func TestWebhookBody_keepWebhookBodyFormats(t *testing.T) {
+	assert := assert.New(t)
+	channel := func(format, body string) map[string]interface{} {
+		return map[string]interface{}{
+			"url":          "https://yourwebhook/endpoint",
+			"operator":     "presence",
+			"body_format":  format,
+			"bodytemplate": body,
+		}
+	}
+
+	t.Run("The configured format is kept when the body is valid in both", func(t *testing.T) {
+		current := []interface{}{channel(bodyFormatText, "status=down")}
+		remote := []interface{}{channel(inferWebhookBodyFormat("status=down"), "status=down")}
+
+		kept := keepWebhookBodyFormats(current, remote)
+		assert.Equal(bodyFormatText, kept[0].(map[string]interface{})["body_format"])
+	})
+
+	t.Run("The remote format is kept when the body is not valid in the configured one", func(t *testing.T) {
+		current := []interface{}{channel(bodyFormatJSON, "{}")}
+		remote := []interface{}{channel(bodyFormatText, "Alert from {{ name }}")}
+
+		kept := keepWebhookBodyFormats(current, remote)
+		assert.Equal(bodyFormatText, kept[0].(map[string]interface{})["body_format"])
+	})
+
+	t.Run("Channels without a counterpart are left as they are", func(t *testing.T) {
+		other := channel(bodyFormatText, "status=down")
+		other["url"] = "https://otherwebhook/endpoint"
+		remote := []interface{}{channel(bodyFormatForm, "status=down")}
+
+		kept := keepWebhookBodyFormats([]interface{}{other}, remote)
+		assert.Equal(bodyFormatForm, kept[0].(map[string]interface{})["body_format"])
+	})
+}
@@ -0,0 +1,158 @@
+package logdna
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// webhookVariables are the placeholders LogDNA substitutes in webhook alerts,
+// along with a sample value used when rendering previews locally.
+// See https://docs.logdna.com/docs/webhook-alerts
+var webhookVariables = map[string]string{
+	"account": "a1b2c3d4e5",
+	"app":     "my-app",
+	"host":    "my-host",
+	"level":   "error",
+	"lines":   "Oct 19 10:00:00 my-host my-app[42]: Something went wrong",
+	"matches": "15",
+	"name":    "My View",
+	"query":   "level:error",
+	"reason":  "15 lines matched in the last 15m",
+	"tag":     "production",
+	"time":    "2021-10-19T10:00:00.000Z",
+	"url":     "https://app.logdna.com/a1b2c3d4e5/logs/view/0123456789",
+}
+
+var webhookPlaceholderRegex = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+func webhookVariableNames() []string {
+	names := make([]string, 0, len(webhookVariables))
+	for name := range webhookVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateWebhookPlaceholders checks every `{{ ... }}` placeholder in `tmpl`
+// against the known webhook variables, and reports unbalanced braces
+func validateWebhookPlaceholders(tmpl string) []error {
+	var errs []error
+
+	for _, match := range webhookPlaceholderRegex.FindAllStringSubmatchIndex(tmpl, -1) {
+		raw := tmpl[match[0]:match[1]]
+		name := strings.TrimSpace(tmpl[match[2]:match[3]])
+		if name == "" {
+			errs = append(errs, fmt.Errorf("empty placeholder %q at offset %d", raw, match[0]))
+			continue
+		}
+		if _, ok := webhookVariables[name]; !ok {
+			errs = append(errs, fmt.Errorf(
+				"unknown placeholder %q at offset %d, expected one of %v",
+				raw, match[0], webhookVariableNames(),
+			))
+		}
+	}
+
+	// Whatever is left after removing placeholders must not open another one.
+	// A stray `}}` is not checked since it is common in nested JSON objects.
+	rest := webhookPlaceholderRegex.ReplaceAllStringFunc(tmpl, func(s string) string {
+		return strings.Repeat(" ", len(s))
+	})
+	if idx := strings.Index(rest, "{{"); idx != -1 {
+		errs = append(errs, fmt.Errorf("unclosed placeholder at offset %d", idx))
+	}
+
+	return errs
+}
+
+// validateWebhookTemplate is a ValidateFunc for string and map attributes
+// that may contain webhook placeholders
+func validateWebhookTemplate(val interface{}, key string) (warns []string, errs []error) {
+	values := map[string]string{}
+	switch v := val.(type) {
+	case string:
+		values[key] = v
+	case map[string]interface{}:
+		for k, hv := range v {
+			if s, ok := hv.(string); ok {
+				values[fmt.Sprintf("%s.%s", key, k)] = s
+			}
+		}
+	}
+
+	for k, v := range values {
+		for _, err := range validateWebhookPlaceholders(v) {
+			errs = append(errs, fmt.Errorf("%q: %s", k, err))
+		}
+	}
+	return
+}
+
+// renderWebhookTemplate substitutes every placeholder with its value in `vars`.
+// Placeholders without a value are left untouched.
+func renderWebhookTemplate(tmpl string, vars map[string]string) string {
+	return webhookPlaceholderRegex.ReplaceAllStringFunc(tmpl, func(s string) string {
+		name := strings.TrimSpace(s[2 : len(s)-2])
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return s
+	})
+}
+
+// renderWebhookBody renders a body so that the values are escaped according to
+// its format: placeholders are substituted inside of the string values of JSON,
+// and query-escaped in forms. Anything else is rendered as plain text.
+func renderWebhookBody(format, body string, vars map[string]string) string {
+	switch format {
+	case bodyFormatText:
+		return renderWebhookTemplate(body, vars)
+	case bodyFormatForm:
+		escaped := make(map[string]string, len(vars))
+		for name, value := range vars {
+			escaped[name] = url.QueryEscape(value)
+		}
+		return renderWebhookTemplate(body, escaped)
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return renderWebhookTemplate(body, vars)
+	}
+
+	// URLs and queries in the body are sent as is, so HTML characters such as
+	// `&` are not escaped
+	var rendered bytes.Buffer
+	encoder := json.NewEncoder(&rendered)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(renderJSONValue(parsed, vars)); err != nil {
+		return renderWebhookTemplate(body, vars)
+	}
+	return strings.TrimSuffix(rendered.String(), "\n")
+}
+
+func renderJSONValue(v interface{}, vars map[string]string) interface{} {
+	switch value := v.(type) {
+	case string:
+		return renderWebhookTemplate(value, vars)
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = renderJSONValue(elem, vars)
+		}
+		return value
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(value))
+		for k, elem := range value {
+			rendered[renderWebhookTemplate(k, vars)] = renderJSONValue(elem, vars)
+		}
+		return rendered
+	}
+	return v
+}
@@ -0,0 +1,85 @@
+package logdna
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookTemplate_validateWebhookPlaceholders(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Known placeholders are valid with or without whitespace", func(t *testing.T) {
+		errs := validateWebhookPlaceholders(`{"description": "{{ matches }} matches found for {{name}}"}`)
+		assert.Empty(errs, "No errors")
+	})
+
+	t.Run("Misspelled placeholders are reported with their offset", func(t *testing.T) {
+		errs := validateWebhookPlaceholders(`{"a": "{{ matchs }}"}`)
+		assert.Len(errs, 1, "There was 1 error")
+		assert.Contains(errs[0].Error(), `unknown placeholder "{{ matchs }}" at offset 7`)
+	})
+
+	t.Run("Empty placeholders are reported", func(t *testing.T) {
+		errs := validateWebhookPlaceholders(`{{ }}`)
+		assert.Len(errs, 1, "There was 1 error")
+		assert.Contains(errs[0].Error(), "empty placeholder")
+	})
+
+	t.Run("Unclosed placeholders are reported", func(t *testing.T) {
+		errs := validateWebhookPlaceholders(`Alert from {{ name`)
+		assert.Len(errs, 1, "There was 1 error")
+		assert.Equal("unclosed placeholder at offset 11", errs[0].Error())
+	})
+
+	t.Run("Nested JSON objects are not mistaken for placeholders", func(t *testing.T) {
+		errs := validateWebhookPlaceholders(`{"fields":{"project":{"key":"test"}}}`)
+		assert.Empty(errs, "No errors")
+	})
+}
+
+func TestWebhookTemplate_validateWebhookTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Validates every header value", func(t *testing.T) {
+		_, errs := validateWebhookTemplate(map[string]interface{}{
+			"X-View":  "{{ name }}",
+			"X-Other": "{{ nope }}",
+		}, "headers")
+		assert.Len(errs, 1, "There was 1 error")
+		assert.Contains(errs[0].Error(), `"headers.X-Other"`)
+	})
+}
+
+func TestWebhookTemplate_renderWebhookBody(t *testing.T) {
+	assert := assert.New(t)
+	vars := map[string]string{"name": `My "quoted" View`, "matches": "3"}
+
+	t.Run("Values are escaped inside JSON strings", func(t *testing.T) {
+		rendered := renderWebhookBody(bodyFormatJSON, `{"summary": "{{ matches }} matches for {{ name }}"}`, vars)
+
+		var parsed map[string]interface{}
+		assert.Nil(json.Unmarshal([]byte(rendered), &parsed), "Rendered body is valid JSON")
+		assert.Equal(`3 matches for My "quoted" View`, parsed["summary"])
+	})
+
+	t.Run("URLs in JSON bodies are not HTML-escaped", func(t *testing.T) {
+		rendered := renderWebhookBody(bodyFormatJSON, `{"link": "{{ url }}?a=1&b=<2>"}`, map[string]string{"url": "https://app.logdna.com/logs"})
+		assert.Equal("{\n  \"link\": \"https://app.logdna.com/logs?a=1&b=<2>\"\n}", rendered)
+	})
+
+	t.Run("Non-JSON bodies are rendered as text", func(t *testing.T) {
+		rendered := renderWebhookBody(bodyFormatText, `{{name}} fired`, vars)
+		assert.Equal(`My "quoted" View fired`, rendered)
+	})
+
+	t.Run("Values are query-escaped in forms", func(t *testing.T) {
+		rendered := renderWebhookBody(bodyFormatForm, `text={{name}}&count={{ matches }}`, vars)
+		assert.Equal(`text=My+%22quoted%22+View&count=3`, rendered)
+	})
+
+	t.Run("Placeholders without a value are left untouched", func(t *testing.T) {
+		assert.Equal("{{ url }}", renderWebhookTemplate("{{ url }}", vars))
+	})
+}